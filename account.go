@@ -0,0 +1,33 @@
+package textbelt
+
+import (
+	"context"
+	"time"
+)
+
+// AccountInfo holds what is known about the account of the key. textbelt only reports the quota,
+// the other fields are derived locally.
+type AccountInfo struct {
+	QuotaRemaining int // Quota remaining as reported by textbelt, possibly cached, see WithQuotaCache
+
+	// EstimatedMessages is how many single-segment messages the quota covers at the cost set with
+	// WithCostPerSegment, country multipliers are not taken into account. Derived, see EstimateCost.
+	EstimatedMessages int
+
+	CheckedAt time.Time // When the account info was put together. Derived.
+}
+
+// AccountInfo returns the quota of the key along with estimates derived from it. textbelt has no
+// endpoint for the plan, reset date or number of sent messages, so those are not available.
+func (t *Textbelt) AccountInfo(ctx context.Context) (*AccountInfo, error) {
+	remaining, err := t.QuotaContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountInfo{
+		QuotaRemaining:    remaining,
+		EstimatedMessages: remaining / t.costPerSegment,
+		CheckedAt:         t.now(),
+	}, nil
+}
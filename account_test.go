@@ -0,0 +1,22 @@
+package textbelt
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAccountInfo(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.SetQuota("key", 9)
+	now := time.Now()
+	texter := New(WithURL(srv.URL), WithKey("key"), WithCostPerSegment(2), WithClock(func() time.Time { return now }))
+
+	info, err := texter.AccountInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.QuotaRemaining != 9 || info.EstimatedMessages != 4 || !info.CheckedAt.Equal(now) {
+		t.Errorf("AccountInfo = %+v, want 9 remaining for 4 messages checked at %v", info, now)
+	}
+}
@@ -0,0 +1,21 @@
+package textbelt
+
+import "context"
+
+// SendResultOrError holds the outcome of SendAsync, exactly one of the fields is set
+type SendResultOrError struct {
+	Result *SendResult
+	Err    error
+}
+
+// SendAsync sends the message in a goroutine and returns a channel yielding its outcome once
+// before being closed. The channel is buffered, so the goroutine exits even if nobody reads it.
+func (t *Textbelt) SendAsync(ctx context.Context, phone, content string, opts ...SendOption) <-chan SendResultOrError {
+	ch := make(chan SendResultOrError, 1)
+	go func() {
+		defer close(ch)
+		res, err := t.SendWithResultContext(ctx, phone, content, opts...)
+		ch <- SendResultOrError{Result: res, Err: err}
+	}()
+	return ch
+}
@@ -0,0 +1,154 @@
+package textbelt
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// StatusBatchError holds the errors of the ids StatusBatch could not get the status of
+type StatusBatchError map[string]error
+
+func (e StatusBatchError) Error() string {
+	ids := make([]string, 0, len(e))
+	for id := range e {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	msgs := make([]string, len(ids))
+	for i, id := range ids {
+		msgs[i] = fmt.Sprintf("%s: %v", id, e[id])
+	}
+	return fmt.Sprintf("textbelt: status of %d messages failed: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// SendBatch sends content to every phone using at most concurrency sends at once.
+// Results are returned in the order of phones, each carrying its own error, so a failed
+// number does not abort the batch. If ctx is done no new sends are started, the
+// remaining results carry ctx.Err() which is also returned.
+func (t *Textbelt) SendBatch(ctx context.Context, phones []string, content string, concurrency int) ([]SendResult, error) {
+	results := make([]SendResult, len(phones))
+	for i, phone := range phones {
+		results[i].Phone = phone
+	}
+
+	launched := runBatch(ctx, len(phones), concurrency, func(i int) {
+		res, err := t.SendWithResultContext(ctx, phones[i], content)
+		if err != nil {
+			results[i].Err = err
+			return
+		}
+		res.Phone = phones[i]
+		results[i] = *res
+	})
+
+	for i := launched; i < len(results); i++ {
+		results[i].Err = ctx.Err()
+	}
+	return results, ctx.Err()
+}
+
+// OTPRequest describes one OTP of GenerateOTPBatch
+type OTPRequest struct {
+	Phone  string // Phone number of the receiver
+	UserID string // UserID - arbitrary ID for the generated OTP
+}
+
+// GenerateOTPBatch generates an OTP for every request using at most concurrency requests at once.
+// Results are returned in the order of reqs, each carrying its own error, so a failed request
+// does not abort the batch. If ctx is done no new OTPs are generated, the remaining results
+// carry ctx.Err() which is also returned.
+func (t *Textbelt) GenerateOTPBatch(ctx context.Context, reqs []OTPRequest, concurrency int) ([]OTPResult, error) {
+	results := make([]OTPResult, len(reqs))
+	for i, req := range reqs {
+		results[i].UserID = req.UserID
+	}
+
+	launched := runBatch(ctx, len(reqs), concurrency, func(i int) {
+		res, err := t.GenerateOTPWithResultContext(ctx, reqs[i].Phone, reqs[i].UserID)
+		if err != nil {
+			results[i].Err = err
+			return
+		}
+		res.UserID = reqs[i].UserID
+		results[i] = *res
+	})
+
+	for i := launched; i < len(results); i++ {
+		results[i].Err = ctx.Err()
+	}
+	return results, ctx.Err()
+}
+
+// StatusBatch gets the status of every id using at most concurrency requests at once.
+// A failed id does not abort the batch, the statuses which could be fetched are returned
+// along with a StatusBatchError holding the error of every failed id.
+func (t *Textbelt) StatusBatch(ctx context.Context, ids []string, concurrency int) (map[string]MessageStatus, error) {
+	statuses := make(map[string]MessageStatus, len(ids))
+	errs := make(StatusBatchError)
+	var mu sync.Mutex
+
+	launched := runBatch(ctx, len(ids), concurrency, func(i int) {
+		status, err := t.StatusContext(ctx, ids[i])
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			errs[ids[i]] = err
+			return
+		}
+		statuses[ids[i]] = status
+	})
+
+	for _, id := range ids[launched:] {
+		errs[id] = ctx.Err()
+	}
+
+	if len(errs) > 0 {
+		return statuses, errs
+	}
+	return statuses, nil
+}
+
+// runBatch calls fn for indexes from 0 to n using at most concurrency goroutines.
+// It stops handing out indexes once ctx is done and returns how many were handed out.
+func runBatch(ctx context.Context, n, concurrency int, fn func(i int)) int {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if concurrency > n {
+		concurrency = n
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+
+	launched := 0
+loop:
+	for ; launched < n; launched++ {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			break loop
+		case jobs <- launched:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return launched
+}
@@ -0,0 +1,105 @@
+package textbelt
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without making a request while the circuit breaker is open
+var ErrCircuitOpen = errors.New("textbelt: circuit breaker open")
+
+// breaker stops requests after consecutive failures, it is safe for concurrent use
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time // zero while the circuit is closed
+	trial    bool      // a half-open trial request is in flight
+}
+
+// WithCircuitBreaker enables you to fail fast with ErrCircuitOpen after failureThreshold consecutive
+// failed requests. Once cooldown elapsed a single trial request is let through, closing the circuit
+// if it succeeds and opening it again otherwise. Network errors, 5xx and undecodable responses
+// count as failures, the breaker is shared by all methods of the Textbelt. failureThreshold must be
+// positive and cooldown must not be negative.
+func WithCircuitBreaker(failureThreshold int, cooldown time.Duration) func(*Textbelt) {
+	return func(t *Textbelt) {
+		if failureThreshold <= 0 {
+			t.setErr(&ValidationError{
+				Field:  "failureThreshold",
+				Reason: "must be positive",
+			})
+			return
+		}
+		if cooldown < 0 {
+			t.setErr(&ValidationError{
+				Field:  "cooldown",
+				Reason: "must not be negative",
+			})
+			return
+		}
+		t.breaker = &breaker{
+			threshold: failureThreshold,
+			cooldown:  cooldown,
+		}
+	}
+}
+
+// allow reports whether a request may be made and whether it is the half-open trial
+func (b *breaker) allow(now time.Time) (trial bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case b.openedAt.IsZero():
+		return false, nil
+	case b.trial || now.Sub(b.openedAt) < b.cooldown:
+		return false, ErrCircuitOpen
+	default:
+		b.trial = true
+		return true, nil
+	}
+}
+
+// record updates the state with the outcome of an allowed request
+func (b *breaker) record(trial bool, err error, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if trial {
+		b.trial = false
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		// says nothing about the endpoint
+	case !isFailure(err):
+		b.failures = 0
+		b.openedAt = time.Time{}
+	case trial:
+		b.openedAt = now
+	default:
+		b.failures++
+		if b.failures >= b.threshold {
+			b.openedAt = now
+		}
+	}
+}
+
+// isFailure reports whether err means the endpoint is not working
+func isFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var uerr *url.Error
+	var herr *HTTPError
+	var derr *DecodeError
+	return errors.As(err, &uerr) || errors.As(err, &herr) && herr.StatusCode >= 500 || errors.As(err, &derr) ||
+		errors.Is(err, ErrEmptyResponse)
+}
@@ -0,0 +1,112 @@
+package textbelt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	failure := &url.Error{Op: "Post", URL: "http://textbelt.test/text", Err: errors.New("connection refused")}
+	b := &breaker{threshold: 2, cooldown: time.Minute}
+	now := time.Now()
+
+	record := func(err error) {
+		t.Helper()
+		trial, aerr := b.allow(now)
+		if aerr != nil {
+			t.Fatalf("allow refused a request while the circuit was closed: %v", aerr)
+		}
+		b.record(trial, err, now)
+	}
+
+	record(failure)
+	record(nil)
+	record(failure)
+	if _, err := b.allow(now); err != nil {
+		t.Fatalf("circuit opened after non-consecutive failures: %v", err)
+	}
+
+	// neither client errors nor cancellations say the endpoint is failing
+	record(&HTTPError{StatusCode: http.StatusBadRequest})
+	record(&APIError{Message: "Out of quota"})
+	record(context.Canceled)
+	record(context.DeadlineExceeded)
+	if _, err := b.allow(now); err != nil {
+		t.Fatalf("circuit opened after errors that are not failures: %v", err)
+	}
+
+	record(failure)
+	record(&HTTPError{StatusCode: http.StatusBadGateway})
+	if _, err := b.allow(now); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow after %d failures returned %v, want ErrCircuitOpen", b.threshold, err)
+	}
+
+	// a failed trial opens the circuit again
+	now = now.Add(b.cooldown)
+	trial, err := b.allow(now)
+	if err != nil || !trial {
+		t.Fatalf("allow after the cooldown returned %v, %v, want a trial", trial, err)
+	}
+	if _, err := b.allow(now); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("second request during the trial returned %v, want ErrCircuitOpen", err)
+	}
+	b.record(trial, &DecodeError{Err: errors.New("invalid character")}, now)
+	if _, err := b.allow(now); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow after a failed trial returned %v, want ErrCircuitOpen", err)
+	}
+
+	// a successful trial closes it
+	now = now.Add(b.cooldown)
+	trial, err = b.allow(now)
+	if err != nil || !trial {
+		t.Fatalf("allow after the cooldown returned %v, %v, want a trial", trial, err)
+	}
+	b.record(trial, nil, now)
+	if trial, err := b.allow(now); err != nil || trial {
+		t.Errorf("allow after a successful trial returned %v, %v, want a closed circuit", trial, err)
+	}
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.SetResponse("/text", http.StatusServiceUnavailable, "Service Unavailable")
+
+	texter := New(WithURL(srv.URL), WithCircuitBreaker(3, time.Minute))
+	for i := range 5 {
+		_, err := texter.Send("+15555555555", "test message")
+		if open := errors.Is(err, ErrCircuitOpen); open != (i >= 3) {
+			t.Errorf("send %d returned %v", i+1, err)
+		}
+	}
+	if n := len(srv.Requests()); n != 3 {
+		t.Errorf("got %d requests, want the breaker to stop after 3", n)
+	}
+}
+
+func TestWithCircuitBreakerInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		threshold int
+		cooldown  time.Duration
+		field     string
+	}{
+		{threshold: 0, cooldown: time.Second, field: "failureThreshold"},
+		{threshold: -1, cooldown: time.Second, field: "failureThreshold"},
+		{threshold: 1, cooldown: -time.Second, field: "cooldown"},
+	} {
+		name := fmt.Sprintf("WithCircuitBreaker(%d, %v)", tc.threshold, tc.cooldown)
+		_, err := NewWithError(WithCircuitBreaker(tc.threshold, tc.cooldown))
+		var verr *ValidationError
+		if !errors.As(err, &verr) || verr.Field != tc.field {
+			t.Errorf("%s: NewWithError returned %v, want a %s ValidationError", name, err, tc.field)
+		}
+	}
+
+	if _, err := NewWithError(WithCircuitBreaker(1, 0)); err != nil {
+		t.Errorf("WithCircuitBreaker(1, 0): NewWithError returned %v", err)
+	}
+}
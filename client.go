@@ -0,0 +1,15 @@
+package textbelt
+
+// Client is the set of textbelt operations most callers depend on, *Textbelt implements it
+// so a fake can be injected in tests. It is intentionally limited to sending messages,
+// checking their status and quota and the basic OTP flow, richer variants such as the
+// Context and WithResult methods are left on *Textbelt.
+type Client interface {
+	Send(phone, content string) (string, error)
+	Status(id string) (MessageStatus, error)
+	Quota() (int, error)
+	GenerateOTP(phone, userid string) (string, error)
+	VerifyOTP(otp, userid string) (bool, error)
+}
+
+var _ Client = (*Textbelt)(nil)
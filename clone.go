@@ -0,0 +1,76 @@
+package textbelt
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Clone returns a new Textbelt with the configuration of t and the options applied on top,
+// t itself is not changed. An option error of t is kept by the clone.
+//
+// The clone shares the rate limiter, so both count against the same limit, as well as the
+// http.Client passed with WithHTTPClient, a UsedOTPStore passed with WithUsedOTPStore, the logger,
+// the tracer and the metrics hook. All other runtime state starts fresh: the quota cache and
+// watermark, the circuit breaker, idempotency keys, used and tracked OTPs, dry-run recordings and
+// the background quota refresh. The test mode starts with the value of t and is set separately.
+// Without WithHTTPClient the clone builds its own client, so WithTimeout and the transport options
+// passed to Clone take effect.
+func (t *Textbelt) Clone(options ...func(*Textbelt)) *Textbelt {
+	c := *t
+
+	c.keys = slices.Clone(t.keys)
+	c.headers = t.headers.Clone()
+	c.multipliers = maps.Clone(t.multipliers)
+	c.senders = maps.Clone(t.senders)
+	c.fieldMap = maps.Clone(t.fieldMap)
+	if t.fetchedKey != nil {
+		c.fetchedKey = new(atomic.Pointer[string])
+	}
+	if !t.customClient {
+		c.client = nil
+	}
+
+	c.testMode = new(atomic.Bool)
+	c.testMode.Store(t.testMode.Load())
+	c.closeOnce = new(sync.Once)
+	c.closed = new(atomic.Bool)
+	c.stop = nil
+	c.done = nil
+
+	if t.quota != nil {
+		c.quota = &quotaCache{ttl: t.quota.ttl}
+	}
+	if t.watermark != nil {
+		c.watermark = &quotaWatermark{
+			threshold: t.watermark.threshold,
+			cb:        t.watermark.cb,
+		}
+	}
+	if t.breaker != nil {
+		c.breaker = &breaker{
+			threshold: t.breaker.threshold,
+			cooldown:  t.breaker.cooldown,
+		}
+	}
+	if t.dryRun != nil {
+		c.dryRun = &recorder{
+			otps: make(map[string]string),
+		}
+	}
+	if _, ok := t.usedOTPs.(*memoryOTPStore); ok {
+		c.usedOTPs = nil
+	}
+
+	c.idempotency = newIdempotencyStore()
+	c.idempotency.ttl = t.idempotency.ttl
+	c.otpExpiry = newOTPExpiry()
+
+	for _, opt := range options {
+		opt(&c)
+	}
+
+	c.start()
+	return &c
+}
@@ -0,0 +1,49 @@
+package textbelt
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCloneLeavesOriginalUnaffected(t *testing.T) {
+	srv := newFakeServer(t)
+	base := New(
+		WithURL(srv.URL),
+		WithKey("base"),
+		WithDefaultHeaders(http.Header{"X-Tenant": {"base"}}),
+		WithCountryMultipliers(map[string]int{"GB": 2}),
+	)
+	defer base.Close()
+
+	clone := base.Clone(
+		WithKey("tenant"),
+		WithTimeout(time.Minute),
+		WithDefaultHeaders(http.Header{"X-Tenant": {"tenant"}}),
+		WithCountryMultipliers(map[string]int{"GB": 5}),
+	)
+	defer clone.Close()
+	clone.SetTestMode(true)
+
+	if got := base.Timeout(); got != 5*time.Second {
+		t.Errorf("base timeout = %v, want 5s", got)
+	}
+	if got := clone.Timeout(); got != time.Minute {
+		t.Errorf("clone timeout = %v, want 1m", got)
+	}
+	if base.key != "base" || base.headers.Get("X-Tenant") != "base" || base.multipliers["GB"] != 2 {
+		t.Errorf("base changed: key %q, headers %v, multipliers %v", base.key, base.headers, base.multipliers)
+	}
+	if base.testMode.Load() {
+		t.Error("test mode of the clone changed the base")
+	}
+	if clone.url != base.url {
+		t.Errorf("clone url = %q, want %q", clone.url, base.url)
+	}
+	if _, err := clone.Send("+15555555555", "test message"); err != nil {
+		t.Errorf("clone Send: %v", err)
+	}
+	if requests := srv.Requests(); len(requests) != 1 || requests[0].Values.Get("key") != "tenant_test" {
+		t.Errorf("clone sent %+v, want one send with the tenant test key", requests)
+	}
+}
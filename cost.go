@@ -0,0 +1,63 @@
+package textbelt
+
+import "strings"
+
+const defaultCostPerSegment = 1
+
+// WithCostPerSegment enables you to set how many quota credits a single SMS segment costs
+// in EstimateCost, otherwise 1 will be used. credits must be positive.
+func WithCostPerSegment(credits int) func(*Textbelt) {
+	return func(t *Textbelt) {
+		if credits <= 0 {
+			t.setErr(&ValidationError{
+				Field:  "costPerSegment",
+				Reason: "must be positive",
+			})
+			return
+		}
+		t.costPerSegment = credits
+	}
+}
+
+// WithCountryMultipliers enables you to make EstimateCost multiply the cost of messages to
+// some countries, keyed by ISO 3166-1 alpha-2 region such as "GB"
+func WithCountryMultipliers(multipliers map[string]int) func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.multipliers = make(map[string]int, len(multipliers))
+		for region, m := range multipliers {
+			t.multipliers[strings.ToUpper(region)] = m
+		}
+	}
+}
+
+// EstimateCost returns how many quota credits sending content to phone is expected to use,
+// without making a request. It assumes every segment of the message, see SegmentCount, costs
+// the same number of credits set with WithCostPerSegment, multiplied by the multiplier of the
+// destination country set with WithCountryMultipliers. The country is only determined for
+// numbers in E.164 format and numbers of the North American Numbering Plan count as US.
+// Textbelt pricing may change, so treat the result as an estimate.
+func (t *Textbelt) EstimateCost(phone, content string) (int, error) {
+	if phone == "" {
+		return 0, &ValidationError{
+			Field:  "phone",
+			Reason: "is empty",
+		}
+	}
+
+	multiplier := 1
+	if strings.HasPrefix(phone, "+") {
+		if !e164.MatchString(phone) {
+			return 0, &ValidationError{
+				Field:  "phone",
+				Reason: "not in E.164 format",
+			}
+		}
+		if _, region, ok := phoneRegion(phone); ok {
+			if m, ok := t.multipliers[region]; ok {
+				multiplier = m
+			}
+		}
+	}
+
+	return SegmentCount(content) * t.costPerSegment * multiplier, nil
+}
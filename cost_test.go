@@ -0,0 +1,46 @@
+package textbelt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEstimateCost(t *testing.T) {
+	texter := New(WithCostPerSegment(2), WithCountryMultipliers(map[string]int{"gb": 3}))
+	long := strings.Repeat("a", 161)
+
+	for _, tc := range []struct {
+		phone, content string
+		want           int
+	}{
+		{phone: "+15555555555", content: "hi", want: 2},
+		{phone: "+15555555555", content: long, want: 4},
+		{phone: "+447700900123", content: "hi", want: 6},
+		{phone: "+447700900123", content: long, want: 12},
+		{phone: "5555555555", content: "hi", want: 2},
+	} {
+		got, err := texter.EstimateCost(tc.phone, tc.content)
+		if err != nil || got != tc.want {
+			t.Errorf("EstimateCost(%q, %d chars) = %d, %v, want %d", tc.phone, len(tc.content), got, err, tc.want)
+		}
+	}
+
+	for _, phone := range []string{"", "+1 555"} {
+		_, err := texter.EstimateCost(phone, "hi")
+		var verr *ValidationError
+		if !errors.As(err, &verr) || verr.Field != "phone" {
+			t.Errorf("EstimateCost(%q) returned %v, want a phone ValidationError", phone, err)
+		}
+	}
+}
+
+func TestWithCostPerSegmentInvalid(t *testing.T) {
+	for _, credits := range []int{0, -1} {
+		_, err := NewWithError(WithCostPerSegment(credits))
+		var verr *ValidationError
+		if !errors.As(err, &verr) || verr.Field != "costPerSegment" {
+			t.Errorf("WithCostPerSegment(%d): NewWithError returned %v, want a costPerSegment ValidationError", credits, err)
+		}
+	}
+}
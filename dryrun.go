@@ -0,0 +1,94 @@
+package textbelt
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+)
+
+// recorder stores the requests made in dry-run mode, it is safe for concurrent use
+type recorder struct {
+	mu     sync.Mutex
+	values []url.Values
+	otps   map[string]string
+}
+
+// WithDryRun enables you to prepare messages and OTPs without making any network calls.
+// Send and OTP methods return deterministic fake IDs and OTPs and the values which would
+// have been sent are available through Recorded. Quota and Status are not affected.
+func WithDryRun() func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.dryRun = &recorder{
+			otps: make(map[string]string),
+		}
+	}
+}
+
+// Recorded returns the values of every request prepared in dry-run mode, in order
+func (t *Textbelt) Recorded() []url.Values {
+	if t.dryRun == nil {
+		return nil
+	}
+
+	t.dryRun.mu.Lock()
+	defer t.dryRun.mu.Unlock()
+
+	recorded := make([]url.Values, len(t.dryRun.values))
+	for i, v := range t.dryRun.values {
+		recorded[i] = cloneValues(v)
+	}
+	return recorded
+}
+
+// handle returns the fake response for the request, ok is false if the request is not faked
+func (rec *recorder) handle(method, path string, values url.Values) (r *response, ok bool) {
+	switch {
+	case method == http.MethodPost && path == "/text":
+	case method == http.MethodPost && path == "/otp/generate":
+	case method == http.MethodGet && path == "/otp/verify":
+	default:
+		return nil, false
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	rec.values = append(rec.values, cloneValues(values))
+	n := len(rec.values)
+
+	switch path {
+	case "/text":
+		return &response{
+			Success: true,
+			ID:      textID(fmt.Sprintf("dryrun-%d", n)),
+		}, true
+	case "/otp/generate":
+		length, err := strconv.Atoi(values.Get("length"))
+		if err != nil || length <= 0 {
+			length = 6
+		}
+		otp := fmt.Sprintf("%0*d", length, n)
+		rec.otps[values.Get("userid")] = otp
+		return &response{
+			Success: true,
+			ID:      textID(fmt.Sprintf("dryrun-%d", n)),
+			OTP:     otp,
+		}, true
+	default:
+		otp, found := rec.otps[values.Get("userid")]
+		return &response{
+			Success:  true,
+			ValidOTP: found && otp == values.Get("otp"),
+		}, true
+	}
+}
+
+func cloneValues(v url.Values) url.Values {
+	c := make(url.Values, len(v))
+	for k, vs := range v {
+		c[k] = append([]string(nil), vs...)
+	}
+	return c
+}
@@ -0,0 +1,53 @@
+package textbelt
+
+import "testing"
+
+func TestDryRun(t *testing.T) {
+	// any request would fail against the closed port
+	texter := New(WithURL("http://127.0.0.1:1"), WithKey("key"), WithDryRun())
+
+	id, err := texter.Send("+15555555555", "test message")
+	if err != nil || id != "dryrun-1" {
+		t.Fatalf("Send = %q, %v, want dryrun-1", id, err)
+	}
+
+	otp, err := texter.GenerateCustomOTP(&CustomOTP{Phone: "+15555555555", UserID: "u1", Length: 4})
+	if err != nil || otp != "0002" {
+		t.Fatalf("GenerateCustomOTP = %q, %v, want 0002", otp, err)
+	}
+
+	for _, tt := range []struct {
+		otp, userid string
+		want        bool
+	}{
+		{otp: otp, userid: "u1", want: true},
+		{otp: "0000", userid: "u1"},
+		{otp: otp, userid: "u2"},
+	} {
+		valid, err := texter.VerifyOTP(tt.otp, tt.userid)
+		if err != nil || valid != tt.want {
+			t.Errorf("VerifyOTP(%q, %q) = %v, %v, want %v", tt.otp, tt.userid, valid, err, tt.want)
+		}
+	}
+
+	recorded := texter.Recorded()
+	if len(recorded) != 5 {
+		t.Fatalf("recorded %d requests, want 5", len(recorded))
+	}
+	if got := recorded[0]; got.Get("phone") != "+15555555555" || got.Get("message") != "test message" || got.Get("key") != "key" {
+		t.Errorf("recorded send = %v", got)
+	}
+	if got := recorded[1]; got.Get("userid") != "u1" || got.Get("length") != "4" {
+		t.Errorf("recorded OTP = %v", got)
+	}
+
+	// the recorded values are copies
+	recorded[0].Set("phone", "changed")
+	if got := texter.Recorded()[0].Get("phone"); got != "+15555555555" {
+		t.Errorf("recorded phone changed to %q", got)
+	}
+
+	if got := New().Recorded(); got != nil {
+		t.Errorf("Recorded without dry-run = %v, want nil", got)
+	}
+}
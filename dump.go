@@ -0,0 +1,88 @@
+package textbelt
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+)
+
+// WithHTTPTrace enables you to write the full HTTP request and response of every call to w
+// for low-level debugging. Keys, phone numbers and the values of credential headers such as
+// Authorization, Proxy-Authorization and Cookie are redacted, everything else is written as is.
+func WithHTTPTrace(w io.Writer) func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.dump = w
+	}
+}
+
+func (t *Textbelt) dumpRequest(req *http.Request, values url.Values) {
+	header := req.Header
+	req.Header = redactHeader(header)
+	b, err := httputil.DumpRequestOut(req, true)
+	req.Header = header
+	if err != nil {
+		fmt.Fprintf(t.dump, "textbelt: dumping request: %v\n", err)
+		return
+	}
+	fmt.Fprintf(t.dump, "%s\n\n", t.redactDump(string(b), values))
+}
+
+// dumpResponse writes the response, the body is limited so the size check of decode still applies
+func (t *Textbelt) dumpResponse(resp *http.Response, values url.Values) {
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(resp.Body, t.maxResponseBytes+1), resp.Body}
+
+	header := resp.Header
+	resp.Header = redactHeader(header)
+	b, err := httputil.DumpResponse(resp, true)
+	resp.Header = header
+	if err != nil {
+		fmt.Fprintf(t.dump, "textbelt: dumping response: %v\n", err)
+		return
+	}
+	fmt.Fprintf(t.dump, "%s\n\n", t.redactDump(string(b), values))
+}
+
+// redactDump removes the keys and the phone number in both raw and URL encoded form
+func (t *Textbelt) redactDump(dump string, values url.Values) string {
+	dump = t.redact(dump)
+	for _, key := range t.secretKeys() {
+		dump = strings.ReplaceAll(dump, url.QueryEscape(key), redacted)
+	}
+
+	if phone := values.Get("phone"); phone != "" {
+		dump = strings.ReplaceAll(dump, phone, RedactPhone(phone))
+		dump = strings.ReplaceAll(dump, url.QueryEscape(phone), RedactPhone(phone))
+	}
+	return dump
+}
+
+// redactHeader returns a copy of h with the values of sensitive headers redacted
+func redactHeader(h http.Header) http.Header {
+	c := h.Clone()
+	for name, vs := range c {
+		if sensitiveHeader(name) {
+			for i := range vs {
+				vs[i] = redacted
+			}
+		}
+	}
+	return c
+}
+
+// sensitiveHeader reports whether the header named name carries credentials, e.g. one passed
+// to WithDefaultHeaders
+func sensitiveHeader(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range []string{"auth", "cookie", "key", "password", "secret", "session", "token"} {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
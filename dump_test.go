@@ -0,0 +1,56 @@
+package textbelt
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithHTTPTraceRedactsHeaders(t *testing.T) {
+	var received http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "cookie-from-server"})
+		w.Write([]byte(`{"success":true,"textId":"1"}`))
+	}))
+	defer srv.Close()
+
+	secrets := http.Header{
+		"Authorization":       {"Bearer authorization-secret"},
+		"Proxy-Authorization": {"Basic proxy-secret"},
+		"Cookie":              {"session=cookie-secret"},
+		"X-Api-Token":         {"token-secret"},
+	}
+	var trace bytes.Buffer
+	texter := New(
+		WithURL(srv.URL),
+		WithDefaultHeaders(secrets),
+		WithDefaultHeaders(http.Header{"X-Request-Source": {"billing"}}),
+		WithHTTPTrace(&trace),
+	)
+
+	if _, err := texter.Send("+15555555555", "test message"); err != nil {
+		t.Fatal(err)
+	}
+
+	out := trace.String()
+	for name, vs := range secrets {
+		if got := received.Get(name); got != vs[0] {
+			t.Errorf("server got %s %q, want %q", name, got, vs[0])
+		}
+		if strings.Contains(out, vs[0]) {
+			t.Errorf("trace contains the %s header: %s", name, out)
+		}
+		if !strings.Contains(out, name+": "+redacted) {
+			t.Errorf("trace does not show the %s header redacted: %s", name, out)
+		}
+	}
+	if strings.Contains(out, "cookie-from-server") {
+		t.Errorf("trace contains the cookie set by the server: %s", out)
+	}
+	if !strings.Contains(out, "X-Request-Source: billing") {
+		t.Errorf("trace does not contain the non-sensitive header: %s", out)
+	}
+}
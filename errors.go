@@ -0,0 +1,162 @@
+package textbelt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrAPI matches every error reported by the textbelt API
+	ErrAPI = errors.New("textbelt: api error")
+	// ErrQuotaExceeded is returned when the key has no quota left
+	ErrQuotaExceeded = errors.New("textbelt: out of quota")
+	// ErrFreeTierLimit is returned when the free DefaultKey was already used today, it matches ErrQuotaExceeded
+	ErrFreeTierLimit = fmt.Errorf("textbelt: free key allows one message per day, use your own key: %w", ErrQuotaExceeded)
+	// ErrInvalidPhone is returned when textbelt rejects the phone number
+	ErrInvalidPhone = errors.New("textbelt: invalid phone number")
+	// ErrInvalidKey is returned when textbelt does not recognize the key
+	ErrInvalidKey = errors.New("textbelt: invalid key")
+	// ErrClosed is returned by requests made after Close
+	ErrClosed = errors.New("textbelt: client closed")
+	// ErrNotSupported is returned by methods the textbelt API offers no endpoint for
+	ErrNotSupported = errors.New("textbelt: not supported by the textbelt API")
+	// ErrEmptyResponse is returned when textbelt responds with an empty body
+	ErrEmptyResponse = errors.New("textbelt: empty response")
+	// ErrQuotaUnavailable is returned by Quota when the response does not report the quota
+	ErrQuotaUnavailable = errors.New("textbelt: quota not reported")
+	// ErrResponseTooLarge is returned when the response body exceeds the limit set with WithMaxResponseBytes
+	ErrResponseTooLarge = errors.New("textbelt: response too large")
+)
+
+// APIError is returned when textbelt responds with success set to false.
+// It matches ErrAPI and, if the message is a known one, the corresponding sentinel error.
+type APIError struct {
+	Message        string // Error message returned by textbelt
+	Err            error  // Sentinel error the message maps to, nil if the message is unknown
+	QuotaRemaining int    // Quota remaining reported alongside the error, zero if textbelt did not report it
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return "textbelt: request failed"
+	}
+	return "textbelt: " + e.Message
+}
+
+// Is reports whether target is ErrAPI
+func (e *APIError) Is(target error) bool {
+	return target == ErrAPI
+}
+
+// Unwrap returns the sentinel error the message maps to
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// apiError maps the error message returned by textbelt onto an APIError
+func apiError(msg string) *APIError {
+	m := strings.ToLower(msg)
+
+	var err error
+	switch {
+	case strings.Contains(m, "one test text"), strings.Contains(m, "per day"):
+		err = ErrFreeTierLimit
+	case strings.Contains(m, "quota"):
+		err = ErrQuotaExceeded
+	case strings.Contains(m, "phone"):
+		err = ErrInvalidPhone
+	case strings.Contains(m, "key"):
+		err = ErrInvalidKey
+	}
+
+	return &APIError{
+		Message: msg,
+		Err:     err,
+	}
+}
+
+// checkSuccess returns the APIError of a response with success set to false
+func checkSuccess(r *response) error {
+	if r.Success {
+		return nil
+	}
+	return apiError(r.Error)
+}
+
+// HTTPError is returned when textbelt responds with a status code other than 200.
+// If the body holds the JSON error of textbelt, it unwraps to the APIError the message maps to.
+type HTTPError struct {
+	StatusCode int           // HTTP status code of the response
+	Body       string        // Beginning of the response body
+	RetryAfter time.Duration // Parsed Retry-After header of 429 responses, zero if absent
+	Err        error         // APIError reported in the body, nil if the body is not a textbelt error
+}
+
+func (e *HTTPError) Error() string {
+	msg := fmt.Sprintf("textbelt: unexpected status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	var aerr *APIError
+	if errors.As(e.Err, &aerr) && aerr.Message != "" {
+		msg += ": " + aerr.Message
+	}
+	return msg
+}
+
+// Unwrap returns the APIError reported in the body
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// bodyError returns the APIError of a JSON error body such as {"success":false,"error":"..."},
+// nil if body is anything else
+func bodyError(body []byte) error {
+	var r struct {
+		Success *bool  `json:"success"`
+		Error   string `json:"error"`
+	}
+	if json.Unmarshal(body, &r) != nil || r.Success == nil || *r.Success || r.Error == "" {
+		return nil
+	}
+	return apiError(r.Error)
+}
+
+// DecodeError is returned when the response body is not the JSON textbelt is expected to send,
+// e.g. an HTML error page of a proxy
+type DecodeError struct {
+	Err        error  // Error of the JSON decoder
+	Body       []byte // Beginning of the response body
+	StatusCode int    // HTTP status code of the response
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("textbelt: decoding response with status %d: %v", e.StatusCode, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// parseRetryAfter parses the Retry-After header given either in seconds or as an HTTP date
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+
+	if sec, err := strconv.Atoi(v); err == nil {
+		if sec < 0 {
+			return 0
+		}
+		return time.Duration(sec) * time.Second
+	}
+
+	if at, err := http.ParseTime(v); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
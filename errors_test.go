@@ -0,0 +1,107 @@
+package textbelt
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestHTTPErrorBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     int
+		body     string
+		sentinel error // nil if the error must not unwrap to an APIError
+	}{
+		{name: "invalid phone", code: http.StatusBadRequest, body: `{"success":false,"error":"Invalid phone number"}`, sentinel: ErrInvalidPhone},
+		{name: "invalid key", code: http.StatusForbidden, body: `{"success":false,"error":"Invalid key"}`, sentinel: ErrInvalidKey},
+		{name: "unknown message", code: http.StatusBadRequest, body: `{"success":false,"error":"Something else"}`, sentinel: ErrAPI},
+		{name: "html", code: http.StatusBadGateway, body: `<html>bad gateway</html>`},
+		{name: "success", code: http.StatusInternalServerError, body: `{"success":true}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFakeServer(t)
+			srv.SetResponse("/text", tt.code, tt.body)
+
+			_, err := New(WithURL(srv.URL)).Send("+15555555555", "test message")
+
+			var herr *HTTPError
+			if !errors.As(err, &herr) || herr.StatusCode != tt.code {
+				t.Fatalf("err = %v, want an HTTPError with status %d", err, tt.code)
+			}
+
+			var aerr *APIError
+			if got := errors.As(err, &aerr); got != (tt.sentinel != nil) {
+				t.Errorf("err = %v, unwraps to an APIError: %v", err, got)
+			}
+			if tt.sentinel != nil && !errors.Is(err, tt.sentinel) {
+				t.Errorf("err = %v, want it to match %v", err, tt.sentinel)
+			}
+		})
+	}
+}
+
+func TestAPIErrorSentinels(t *testing.T) {
+	tests := []struct {
+		msg      string
+		sentinel error // nil for unknown messages
+	}{
+		{msg: "Out of quota", sentinel: ErrQuotaExceeded},
+		{msg: "Only one test text message is allowed per day.", sentinel: ErrFreeTierLimit},
+		{msg: "Sorry, free texts are limited to 1 per day", sentinel: ErrFreeTierLimit},
+		{msg: "Invalid phone number", sentinel: ErrInvalidPhone},
+		{msg: "PHONE NUMBER NOT SUPPORTED", sentinel: ErrInvalidPhone},
+		{msg: "Invalid key", sentinel: ErrInvalidKey},
+		{msg: "Message is too long"},
+		{msg: ""},
+	}
+
+	for _, tt := range tests {
+		err := apiError(tt.msg)
+		if !errors.Is(err, ErrAPI) {
+			t.Errorf("apiError(%q) does not match ErrAPI", tt.msg)
+		}
+		if err.Err != tt.sentinel {
+			t.Errorf("apiError(%q) maps to %v, want %v", tt.msg, err.Err, tt.sentinel)
+		}
+	}
+
+	if !errors.Is(apiError("Only one test text message is allowed per day."), ErrQuotaExceeded) {
+		t.Error("ErrFreeTierLimit does not match ErrQuotaExceeded")
+	}
+}
+
+func TestSendErrorQuota(t *testing.T) {
+	zero, three := 0, 3
+	tests := []struct {
+		name     string
+		r        response
+		quota    int
+		sentinel error
+	}{
+		{name: "unknown message without quota", r: response{Error: "Something went wrong", QuotaRemaining: &zero}, sentinel: ErrQuotaExceeded},
+		{name: "unknown message with quota", r: response{Error: "Something went wrong", QuotaRemaining: &three}, quota: 3},
+		{name: "unknown message without a reported quota", r: response{Error: "Something went wrong"}},
+		{name: "known message without quota", r: response{Error: "Invalid phone number", QuotaRemaining: &zero}, sentinel: ErrInvalidPhone},
+	}
+
+	for _, tt := range tests {
+		err := sendError(&tt.r)
+		if err.Err != tt.sentinel {
+			t.Errorf("%s: maps to %v, want %v", tt.name, err.Err, tt.sentinel)
+		}
+		if err.QuotaRemaining != tt.quota {
+			t.Errorf("%s: QuotaRemaining = %d, want %d", tt.name, err.QuotaRemaining, tt.quota)
+		}
+	}
+
+	srv := newFakeServer(t)
+	srv.SetResponse("/text", http.StatusOK, `{"success":false,"error":"Only one test text message is allowed per day.","quotaRemaining":0}`)
+
+	_, err := New(WithURL(srv.URL)).Send("+15555555555", "test message")
+	if !errors.Is(err, ErrFreeTierLimit) || !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Send returned %v, want ErrFreeTierLimit matching ErrQuotaExceeded", err)
+	}
+}
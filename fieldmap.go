@@ -0,0 +1,80 @@
+package textbelt
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// responseFields are the JSON names of the response fields the library decodes
+var responseFields = map[string]bool{
+	"success":        true,
+	"status":         true,
+	"textId":         true,
+	"error":          true,
+	"quotaRemaining": true,
+	"otp":            true,
+	"isValidOtp":     true,
+}
+
+// WithResponseFieldMap enables you to talk to textbelt forks which rename response fields.
+// The keys of fields are the names used by textbelt, e.g. "textId", the values the names the
+// fork uses instead, e.g. "text_id". Only top-level fields can be renamed, fields which are
+// not mapped are decoded as usual. Raw in SendResult and OTPResult keeps the names of the fork.
+func WithResponseFieldMap(fields map[string]string) func(*Textbelt) {
+	return func(t *Textbelt) {
+		m := make(map[string]string, len(fields))
+		for field, name := range fields {
+			if !responseFields[field] {
+				t.setErr(&ValidationError{
+					Field:  "responseFieldMap",
+					Reason: fmt.Sprintf("unknown response field %q", field),
+				})
+				return
+			}
+			if name == "" {
+				t.setErr(&ValidationError{
+					Field:  "responseFieldMap",
+					Reason: fmt.Sprintf("empty name for response field %q", field),
+				})
+				return
+			}
+			m[field] = name
+		}
+		t.fieldMap = m
+	}
+}
+
+// mapFields renames the fields of the response set with WithResponseFieldMap to the names used
+// by textbelt. Bodies which are not a JSON object are returned unchanged for decode to report.
+func (t *Textbelt) mapFields(b []byte) []byte {
+	if len(t.fieldMap) == 0 {
+		return b
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(b, &obj); err != nil || obj == nil {
+		return b
+	}
+
+	// collect first so a field mapped onto the name of another one is not renamed twice
+	values := make(map[string]json.RawMessage, len(t.fieldMap))
+	for field, name := range t.fieldMap {
+		if v, ok := obj[name]; ok {
+			values[field] = v
+		}
+	}
+	for field, name := range t.fieldMap {
+		if _, ok := values[field]; ok {
+			delete(obj, name)
+		}
+	}
+	for field, v := range values {
+		obj[field] = v
+	}
+
+	mapped, err := json.Marshal(obj)
+	if err != nil {
+		return b
+	}
+	return mapped
+}
@@ -0,0 +1,40 @@
+package textbelt
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestWithResponseFieldMap(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.SetResponse("/text", http.StatusOK, `{"ok":true,"text_id":12,"quota_remaining":4}`)
+
+	texter := New(
+		WithURL(srv.URL),
+		WithStrictDecoding(),
+		WithResponseFieldMap(map[string]string{
+			"success":        "ok",
+			"textId":         "text_id",
+			"quotaRemaining": "quota_remaining",
+		}),
+	)
+	res, err := texter.SendMessage(&Message{Phone: "+15555555555", Content: "test message"})
+	if err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	if res.ID != "12" || !res.QuotaReported || res.QuotaRemaining != 4 {
+		t.Errorf("SendMessage = %+v, want ID 12 and quota 4", res)
+	}
+
+	// without the map the renamed fields are unknown
+	if _, err := New(WithURL(srv.URL)).Send("+15555555555", "test message"); err == nil {
+		t.Error("Send without the field map succeeded")
+	}
+
+	_, err = NewWithError(WithResponseFieldMap(map[string]string{"unknown": "x"}))
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Errorf("NewWithError with an unknown field = %v, want a ValidationError", err)
+	}
+}
@@ -0,0 +1,104 @@
+package textbelt
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const defaultIdempotencyTTL = 24 * time.Hour
+
+// idempotencyStore remembers successful sends by their idempotency key, it is safe for concurrent use
+type idempotencyStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*idempotencyEntry
+}
+
+type idempotencyEntry struct {
+	done    chan struct{} // closed once the send finished
+	res     *SendResult
+	err     error
+	expires time.Time // zero while the send is in flight
+}
+
+func newIdempotencyStore() *idempotencyStore {
+	return &idempotencyStore{
+		ttl:     defaultIdempotencyTTL,
+		entries: make(map[string]*idempotencyEntry),
+	}
+}
+
+// WithIdempotencyTTL enables you to set how long SendIdempotent remembers a key, otherwise 24 hours will be used
+func WithIdempotencyTTL(ttl time.Duration) func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.idempotency.ttl = ttl
+	}
+}
+
+// SendIdempotent sends the message unless a message with the same idempotencyKey was already
+// sent successfully within the idempotency TTL, in which case the earlier result is returned.
+// Concurrent calls with the same key wait for the first one. Failed sends are not remembered
+// so they can be retried, keys are kept in memory of this Textbelt only.
+func (t *Textbelt) SendIdempotent(ctx context.Context, idempotencyKey, phone, content string) (*SendResult, error) {
+	return t.idempotency.do(ctx, idempotencyKey, t.now, func() (*SendResult, error) {
+		return t.SendWithResultContext(ctx, phone, content)
+	})
+}
+
+func (s *idempotencyStore) do(ctx context.Context, key string, now func() time.Time, send func() (*SendResult, error)) (*SendResult, error) {
+	for {
+		s.mu.Lock()
+		s.evict(now())
+
+		e, ok := s.entries[key]
+		if !ok {
+			e = &idempotencyEntry{done: make(chan struct{})}
+			s.entries[key] = e
+			s.mu.Unlock()
+			return s.run(key, e, now, send)
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-e.done:
+		}
+
+		if e.err == nil {
+			res := *e.res
+			return &res, nil
+		}
+		// the earlier send failed and was forgotten, try again
+	}
+}
+
+func (s *idempotencyStore) run(key string, e *idempotencyEntry, now func() time.Time, send func() (*SendResult, error)) (*SendResult, error) {
+	res, err := send()
+
+	s.mu.Lock()
+	e.res, e.err = res, err
+	if err != nil {
+		delete(s.entries, key)
+	} else {
+		e.expires = now().Add(s.ttl)
+	}
+	s.mu.Unlock()
+	close(e.done)
+
+	if err != nil {
+		return nil, err
+	}
+	cp := *res
+	return &cp, nil
+}
+
+// evict removes expired entries, s.mu must be held
+func (s *idempotencyStore) evict(now time.Time) {
+	for key, e := range s.entries {
+		if !e.expires.IsZero() && now.After(e.expires) {
+			delete(s.entries, key)
+		}
+	}
+}
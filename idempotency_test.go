@@ -0,0 +1,89 @@
+package textbelt
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSendIdempotent(t *testing.T) {
+	srv := newFakeServer(t)
+	texter := New(WithURL(srv.URL))
+	ctx := context.Background()
+
+	first, err := texter.SendIdempotent(ctx, "a", "+15555555555", "test message")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := texter.SendIdempotent(ctx, "a", "+15555555555", "test message")
+	if err != nil || again.ID != first.ID {
+		t.Errorf("repeated key = %+v, %v, want ID %s", again, err, first.ID)
+	}
+	other, err := texter.SendIdempotent(ctx, "b", "+15555555555", "test message")
+	if err != nil || other.ID == first.ID {
+		t.Errorf("other key = %+v, %v, want a new send", other, err)
+	}
+	if n := len(srv.Requests()); n != 2 {
+		t.Errorf("%d sends, want 2", n)
+	}
+}
+
+func TestSendIdempotentConcurrent(t *testing.T) {
+	srv := newFakeServer(t)
+	texter := New(WithURL(srv.URL))
+
+	var wg sync.WaitGroup
+	ids := make([]string, 20)
+	for i := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := texter.SendIdempotent(context.Background(), "a", "+15555555555", "test message")
+			if err != nil {
+				t.Errorf("SendIdempotent: %v", err)
+				return
+			}
+			ids[i] = res.ID
+		}()
+	}
+	wg.Wait()
+
+	if n := len(srv.Requests()); n != 1 {
+		t.Errorf("%d sends, want 1", n)
+	}
+	for _, id := range ids {
+		if id != ids[0] {
+			t.Errorf("got IDs %v, want all equal", ids)
+			break
+		}
+	}
+}
+
+func TestSendIdempotentFailureAndExpiry(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.SetResponse("/text", http.StatusOK, `{"success":false,"error":"Out of quota"}`)
+	texter := New(WithURL(srv.URL), WithIdempotencyTTL(50*time.Millisecond))
+	ctx := context.Background()
+
+	if _, err := texter.SendIdempotent(ctx, "a", "+15555555555", "test message"); err == nil {
+		t.Fatal("failing send succeeded")
+	}
+
+	// the failure is not remembered
+	srv.SetResponse("/text", http.StatusOK, "")
+	first, err := texter.SendIdempotent(ctx, "a", "+15555555555", "test message")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	again, err := texter.SendIdempotent(ctx, "a", "+15555555555", "test message")
+	if err != nil || again.ID == first.ID {
+		t.Errorf("after the TTL = %+v, %v, want a new send", again, err)
+	}
+	if n := len(srv.Requests()); n != 3 {
+		t.Errorf("%d sends, want 3", n)
+	}
+}
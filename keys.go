@@ -0,0 +1,119 @@
+package textbelt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// WithKeys enables you to pass several API keys, messages and OTPs are sent with the first one
+// and fall over to the next only when textbelt reports the key as out of quota or invalid.
+// Other calls such as Quota use the first key.
+func WithKeys(keys ...string) func(*Textbelt) {
+	return func(t *Textbelt) {
+		if len(keys) == 0 {
+			return
+		}
+		t.key = keys[0]
+		t.keys = keys
+		t.keyFunc = nil
+	}
+}
+
+// WithKeyFromFile enables you to read the API key from the file at path, e.g. a mounted secret.
+// The file is read by New and surrounding whitespace is trimmed.
+func WithKeyFromFile(path string) func(*Textbelt) {
+	return func(t *Textbelt) {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.setErr(fmt.Errorf("textbelt: reading key: %w", err))
+			return
+		}
+
+		key := strings.TrimSpace(string(b))
+		if key == "" {
+			t.setErr(&ValidationError{
+				Field:  "key",
+				Reason: fmt.Sprintf("file %s is empty", path),
+			})
+			return
+		}
+		WithKey(key)(t)
+	}
+}
+
+// WithKeyFunc enables you to fetch the API key before every request, e.g. from a secret manager
+// rotating it. A failing fn fails the request with its error wrapped. fn must be safe for
+// concurrent use and should cache the key itself if fetching it is expensive.
+func WithKeyFunc(fn func() (string, error)) func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.keyFunc = fn
+		t.keys = nil
+		t.fetchedKey = new(atomic.Pointer[string])
+	}
+}
+
+// apiKeys returns the keys to try in order
+func (t *Textbelt) apiKeys() ([]string, error) {
+	if len(t.keys) == 0 {
+		key, err := t.apiKey()
+		if err != nil {
+			return nil, err
+		}
+		return []string{key}, nil
+	}
+
+	keys := make([]string, len(t.keys))
+	for i, key := range t.keys {
+		if t.testMode.Load() {
+			key += testSuffix
+		}
+		keys[i] = key
+	}
+	return keys, nil
+}
+
+// postWithKeys posts values trying every key until one succeeds or fails for a reason other
+// than its quota or validity. It returns the index of the key used for the returned response.
+func (t *Textbelt) postWithKeys(ctx context.Context, op, path string, values url.Values) (*response, int, error) {
+	keys, err := t.apiKeys()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i, key := range keys {
+		values.Set("key", key)
+
+		r, err := t.doChecked(ctx, op, http.MethodPost, path, values, checkSend)
+
+		if err != nil && i < len(keys)-1 && (errors.Is(err, ErrQuotaExceeded) || errors.Is(err, ErrInvalidKey)) {
+			continue
+		}
+		return r, i, err
+	}
+	panic("unreachable")
+}
+
+// sendError converts the failed response of a send into an APIError. Failures textbelt
+// reports with an unknown message but no quota left are treated as ErrQuotaExceeded.
+func sendError(r *response) *APIError {
+	err := apiError(r.Error)
+	err.QuotaRemaining = r.quota()
+	if err.Err == nil && r.QuotaRemaining != nil && *r.QuotaRemaining == 0 {
+		err.Err = ErrQuotaExceeded
+	}
+	return err
+}
+
+// checkSend returns the sendError of a failed send or OTP generation
+func checkSend(r *response) error {
+	if r.Success {
+		return nil
+	}
+	return sendError(r)
+}
@@ -0,0 +1,97 @@
+package textbelt
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// keyServer answers sends and quota requests depending on the key, recording the keys tried
+type keyServer struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (s *keyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.FormValue("key")
+	if strings.HasPrefix(r.URL.Path, "/quota/") {
+		key = strings.TrimPrefix(r.URL.Path, "/quota/")
+	}
+
+	s.mu.Lock()
+	s.keys = append(s.keys, key)
+	s.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(key, "empty"):
+		w.Write([]byte(`{"success":false,"error":"Out of quota","quotaRemaining":0}`))
+	case strings.HasPrefix(key, "bad"):
+		w.Write([]byte(`{"success":false,"error":"Invalid key"}`))
+	case r.FormValue("phone") == "invalid":
+		w.Write([]byte(`{"success":false,"error":"Invalid phone number"}`))
+	default:
+		w.Write([]byte(`{"success":true,"textId":"1","otp":"123456","quotaRemaining":10}`))
+	}
+}
+
+func TestWithKeysFailover(t *testing.T) {
+	tests := []struct {
+		name      string
+		keys      []string
+		phone     string
+		wantKeys  []string
+		wantIndex int
+		wantErr   error
+	}{
+		{name: "first key works", keys: []string{"ok1", "ok2"}, wantKeys: []string{"ok1"}},
+		{name: "quota and key errors fall over", keys: []string{"empty", "bad", "ok"}, wantKeys: []string{"empty", "bad", "ok"}, wantIndex: 2},
+		{name: "other errors stop", keys: []string{"ok1", "ok2"}, phone: "invalid", wantKeys: []string{"ok1"}, wantErr: ErrInvalidPhone},
+		{name: "all keys fail", keys: []string{"empty1", "empty2"}, wantKeys: []string{"empty1", "empty2"}, wantErr: ErrQuotaExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ks := &keyServer{}
+			srv := httptest.NewServer(ks)
+			defer srv.Close()
+
+			phone := tt.phone
+			if phone == "" {
+				phone = "+15555555555"
+			}
+
+			texter := New(WithURL(srv.URL), WithKeys(tt.keys...))
+			res, err := texter.SendMessage(&Message{Phone: phone, Content: "test message"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && res.KeyIndex != tt.wantIndex {
+				t.Errorf("KeyIndex = %d, want %d", res.KeyIndex, tt.wantIndex)
+			}
+			if strings.Join(ks.keys, ",") != strings.Join(tt.wantKeys, ",") {
+				t.Errorf("tried keys %v, want %v", ks.keys, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestWithKeysOTPAndQuota(t *testing.T) {
+	ks := &keyServer{}
+	srv := httptest.NewServer(ks)
+	defer srv.Close()
+
+	texter := New(WithURL(srv.URL), WithKeys("empty", "ok"))
+	res, err := texter.GenerateOTPWithResult("+15555555555", "u1")
+	if err != nil || res.KeyIndex != 1 {
+		t.Fatalf("GenerateOTPWithResult = %+v, %v, want KeyIndex 1", res, err)
+	}
+
+	// other calls only use the first key
+	texter.QuotaFresh()
+	if got := ks.keys[len(ks.keys)-1]; got != "empty" {
+		t.Errorf("Quota used key %q, want the first one", got)
+	}
+}
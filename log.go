@@ -0,0 +1,162 @@
+package textbelt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const redacted = "REDACTED"
+
+// WithLogger enables you to log every request and response made to textbelt.
+// Keys and OTPs are never logged and phone numbers are redacted with RedactPhone.
+func WithLogger(l *slog.Logger) func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.logger = l
+	}
+}
+
+func (t *Textbelt) logRequest(ctx context.Context, req *http.Request, values url.Values) {
+	if t.logger == nil {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", t.redactURL(req.URL)),
+	}
+	if phone := values.Get("phone"); phone != "" {
+		attrs = append(attrs, slog.String("phone", RedactPhone(phone)))
+	}
+	t.logger.LogAttrs(ctx, slog.LevelDebug, "textbelt request", attrs...)
+}
+
+func (t *Textbelt) logResponse(ctx context.Context, statusCode int, r *response, err error) {
+	if t.logger == nil {
+		return
+	}
+
+	if err != nil {
+		t.logger.LogAttrs(ctx, slog.LevelError, "textbelt request failed",
+			slog.Int("status_code", statusCode),
+			slog.String("error", t.redact(err.Error())),
+		)
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.Int("status_code", statusCode),
+		slog.Bool("success", r.Success),
+		slog.String("text_id", string(r.ID)),
+	}
+	if r.QuotaRemaining != nil {
+		attrs = append(attrs, slog.Int("quota_remaining", *r.QuotaRemaining))
+	}
+	t.logger.LogAttrs(ctx, slog.LevelDebug, "textbelt response", attrs...)
+}
+
+// quotaKey matches the key in the path of quota requests
+var quotaKey = regexp.MustCompile(`/quota/[^/?#\s"]+`)
+
+// otpQuery matches the OTP in the query of verify requests
+var otpQuery = regexp.MustCompile(`([?&]otp=)[^&#\s"]*`)
+
+// redact removes the key and the OTP being verified from s, the key of quota requests is removed
+// even if it is not a configured one, see QuotaFor
+func (t *Textbelt) redact(s string) string {
+	for _, key := range t.secretKeys() {
+		s = strings.ReplaceAll(s, key, redacted)
+		s = strings.ReplaceAll(s, url.PathEscape(key), redacted)
+	}
+	s = quotaKey.ReplaceAllString(s, "/quota/"+redacted)
+	return otpQuery.ReplaceAllString(s, "${1}"+redacted)
+}
+
+// secretKeys returns the keys to redact, the public free key is left alone
+// since it would also match the textbelt host
+func (t *Textbelt) secretKeys() []string {
+	candidates := t.keys
+	switch {
+	case t.keyFunc != nil:
+		candidates = nil
+		if k := t.fetchedKey.Load(); k != nil {
+			candidates = []string{*k}
+		}
+	case len(t.keys) == 0:
+		candidates = []string{t.key}
+	}
+
+	// the keys are prefixes of their test variants, so those are redacted too
+	var keys []string
+	for _, k := range candidates {
+		if k != "" && strings.TrimSuffix(k, testSuffix) != DefaultKey {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// redactError removes the key from the URL carried by err
+func (t *Textbelt) redactError(err error) error {
+	var uerr *url.Error
+	if errors.As(err, &uerr) {
+		uerr.URL = t.redact(uerr.URL)
+	}
+	return err
+}
+
+// String returns the configuration of the Textbelt with the key masked
+func (t *Textbelt) String() string {
+	key := maskKey(t.key)
+	if t.keyFunc != nil {
+		key = "from func"
+	}
+	return fmt.Sprintf("Textbelt{url: %s, key: %s, timeout: %s}", t.url, key, t.timeout)
+}
+
+// maskKey keeps only the first and last 4 characters of long keys, short keys are masked completely
+func maskKey(key string) string {
+	if len(key) < 12 {
+		return strings.Repeat("*", len(key))
+	}
+	return key[:4] + "..." + key[len(key)-4:]
+}
+
+// redactURL returns u with the key removed from both the path and the query, and the OTP from the query
+func (t *Textbelt) redactURL(u *url.URL) string {
+	c := *u
+	c.Path = t.redact(c.Path)
+	c.RawPath = ""
+
+	if c.RawQuery != "" {
+		q := c.Query()
+		for _, k := range []string{"key", "otp"} {
+			if q.Has(k) {
+				q.Set(k, redacted)
+			}
+		}
+		c.RawQuery = q.Encode()
+	}
+	return c.String()
+}
+
+// RedactPhone masks the phone number for logs, keeping only the country calling code of E.164
+// numbers and the last two digits, e.g. +15555555589 becomes +1********89. It is used for every
+// phone number the library logs or puts into errors.
+func RedactPhone(phone string) string {
+	prefix := ""
+	if code, _, ok := phoneRegion(phone); ok {
+		prefix = "+" + code
+	}
+
+	rest := phone[len(prefix):]
+	if len(rest) <= 2 {
+		return prefix + strings.Repeat("*", len(rest))
+	}
+	return prefix + strings.Repeat("*", len(rest)-2) + rest[len(rest)-2:]
+}
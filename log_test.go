@@ -0,0 +1,58 @@
+package textbelt
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestQuotaForRedactsKey(t *testing.T) {
+	const key = "adhocsecretkey99"
+
+	var logs, trace bytes.Buffer
+	texter := New(
+		WithURL("http://127.0.0.1:1"),
+		WithLogger(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+		WithHTTPTrace(&trace),
+	)
+
+	_, err := texter.QuotaFor(context.Background(), key)
+	if err == nil {
+		t.Fatal("QuotaFor succeeded against a closed port")
+	}
+
+	for name, s := range map[string]string{
+		"error": err.Error(),
+		"log":   logs.String(),
+		"trace": trace.String(),
+	} {
+		if strings.Contains(s, key) {
+			t.Errorf("%s contains the key: %s", name, s)
+		}
+	}
+}
+
+func TestVerifyOTPRedactsOTP(t *testing.T) {
+	srv := newFakeServer(t)
+
+	for _, url := range []string{srv.URL, "http://127.0.0.1:1"} {
+		var logs, trace bytes.Buffer
+		texter := New(
+			WithURL(url),
+			WithKey("secretkey"),
+			WithLogger(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+			WithHTTPTrace(&trace),
+		)
+
+		_, err := texter.VerifyOTP("000001", "u1")
+		out := logs.String() + trace.String()
+		if err != nil {
+			out += err.Error()
+		}
+		if strings.Contains(out, "000001") {
+			t.Errorf("output against %s contains the OTP: %s", url, out)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package textbelt
+
+import "time"
+
+// MetricsHook is called after every call to textbelt, so it can be backed by any metrics system
+type MetricsHook interface {
+	// ObserveRequest receives the name of the operation such as "Send", "Quota", "Status",
+	// "GenerateOTP" or "VerifyOTP", how long it took including retries and its error, if any.
+	// Failures textbelt reports with success set to false are passed as the returned APIError.
+	// With WithKeys every key tried is observed as a request of its own.
+	ObserveRequest(op string, duration time.Duration, err error)
+}
+
+// WithMetrics enables you to observe every call with m. Panics inside m are recovered
+// so they never break the request.
+func WithMetrics(m MetricsHook) func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.metrics = m
+	}
+}
+
+func (t *Textbelt) observe(op string, start time.Time, err error) {
+	defer func() {
+		_ = recover()
+	}()
+	t.metrics.ObserveRequest(op, time.Since(start), err)
+}
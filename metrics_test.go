@@ -0,0 +1,49 @@
+package textbelt
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingHook records every observation
+type recordingHook struct {
+	mu   sync.Mutex
+	ops  []string
+	errs []error
+}
+
+func (h *recordingHook) ObserveRequest(op string, _ time.Duration, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.ops = append(h.ops, op)
+	h.errs = append(h.errs, err)
+}
+
+func TestMetricsObserveAPIErrors(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.SetResponse("/text", http.StatusOK, `{"success":false,"error":"Invalid phone number"}`)
+
+	hook := &recordingHook{}
+	texter := New(WithURL(srv.URL), WithMetrics(hook))
+
+	_, sendErr := texter.Send("+15555555555", "test message")
+	texter.Status("1")
+	texter.VerifyOTP("123456", "u1")
+
+	if len(hook.ops) != 3 {
+		t.Fatalf("observed %v, want 3 calls", hook.ops)
+	}
+	if hook.ops[0] != "Send" || !errors.Is(hook.errs[0], ErrInvalidPhone) || hook.errs[0] != sendErr {
+		t.Errorf("Send observed with %v, want the returned %v", hook.errs[0], sendErr)
+	}
+	if hook.ops[1] != "Status" || hook.errs[1] != nil {
+		t.Errorf("Status observed with %v, want nil", hook.errs[1])
+	}
+	if hook.ops[2] != "VerifyOTP" || hook.errs[2] != nil {
+		t.Errorf("wrong OTP observed with %v, want nil", hook.errs[2])
+	}
+}
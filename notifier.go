@@ -0,0 +1,29 @@
+package textbelt
+
+import (
+	"log/slog"
+	"time"
+)
+
+// NewNotifier creates a Textbelt with defaults suited for sending notifications with key, it is
+// New with these options applied first:
+//
+//	WithKey(key)
+//	WithTimeout(10 * time.Second)
+//	WithRetry(4, ExponentialBackoff{BaseDelay: 500 * time.Millisecond, MaxDelay: 5 * time.Second})
+//	WithRateLimit(5, 10)
+//	WithLogger(slog.Default())
+//
+// A failing request is retried up to 3 times. Failed requests are logged at error level with
+// phone numbers redacted, see RedactPhone. The passed options are applied afterwards, so they can
+// override any of the defaults.
+func NewNotifier(key string, options ...func(*Textbelt)) *Textbelt {
+	defaults := []func(*Textbelt){
+		WithKey(key),
+		WithTimeout(10 * time.Second),
+		WithRetry(4, ExponentialBackoff{BaseDelay: 500 * time.Millisecond, MaxDelay: 5 * time.Second}),
+		WithRateLimit(5, 10),
+		WithLogger(slog.Default()),
+	}
+	return New(append(defaults, options...)...)
+}
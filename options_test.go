@@ -0,0 +1,41 @@
+package textbelt
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithTimeoutNonPositive(t *testing.T) {
+	srv := newFakeServer(t)
+
+	for _, timeout := range []time.Duration{0, -time.Second} {
+		texter := New(WithURL(srv.URL), WithTimeout(timeout))
+		if got := texter.client.Timeout; got > 0 {
+			t.Errorf("WithTimeout(%v): client timeout is %v, want none", timeout, got)
+		}
+		if _, err := texter.Send("+15555555555", "test message"); err != nil {
+			t.Errorf("WithTimeout(%v): Send failed: %v", timeout, err)
+		}
+
+		_, err := NewWithError(WithURL(srv.URL), WithTimeout(timeout))
+		var verr *ValidationError
+		if !errors.As(err, &verr) || verr.Field != "timeout" {
+			t.Errorf("WithTimeout(%v): NewWithError returned %v, want a timeout ValidationError", timeout, err)
+		}
+	}
+}
+
+func TestNewWithErrorInvalidOptions(t *testing.T) {
+	for name, opt := range map[string]func(*Textbelt){
+		"url":              WithURL("://bad"),
+		"maxResponseBytes": WithMaxResponseBytes(0),
+	} {
+		if _, err := NewWithError(opt); err == nil {
+			t.Errorf("%s: NewWithError returned no error", name)
+		}
+		if _, err := New(opt).Send("+15555555555", "test message"); err == nil {
+			t.Errorf("%s: Send with an invalid option returned no error", name)
+		}
+	}
+}
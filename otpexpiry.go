@@ -0,0 +1,57 @@
+package textbelt
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultOTPLifetime is how long textbelt keeps an OTP valid when no lifetime is passed
+const defaultOTPLifetime = 180 * time.Second
+
+// otpExpiry remembers when the last OTP generated for every userid expires,
+// it is safe for concurrent use
+type otpExpiry struct {
+	mu      sync.Mutex
+	expires map[string]time.Time
+}
+
+func newOTPExpiry() *otpExpiry {
+	return &otpExpiry{
+		expires: make(map[string]time.Time),
+	}
+}
+
+// generated records the OTP generated for userid at now, lifetime in seconds or 0 for the default
+func (e *otpExpiry) generated(userid string, lifetime int, now time.Time) {
+	d := defaultOTPLifetime
+	if lifetime > 0 {
+		d = time.Duration(lifetime) * time.Second
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.evict(now)
+	e.expires[userid] = now.Add(d)
+}
+
+// remaining returns how long the last OTP generated for userid is valid, zero if it expired or is unknown
+func (e *otpExpiry) remaining(userid string, now time.Time) time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.evict(now)
+	exp, ok := e.expires[userid]
+	if !ok {
+		return 0
+	}
+	return exp.Sub(now)
+}
+
+func (e *otpExpiry) evict(now time.Time) {
+	for userid, exp := range e.expires {
+		if !now.Before(exp) {
+			delete(e.expires, userid)
+		}
+	}
+}
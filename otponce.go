@@ -0,0 +1,85 @@
+package textbelt
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOTPAlreadyUsed is returned by VerifyOTPOnce when the OTP was already verified for the userid
+var ErrOTPAlreadyUsed = errors.New("textbelt: otp already used")
+
+// UsedOTPStore records OTPs verified by VerifyOTPOnce. Implementations backed by a shared
+// database or cache enable the single-use guard across several processes.
+type UsedOTPStore interface {
+	// MarkUsed records the otp and userid pair for ttl and reports whether it was already recorded.
+	// It must be atomic so only one of concurrent calls for the same pair reports false.
+	MarkUsed(ctx context.Context, otp, userid string, ttl time.Duration) (used bool, err error)
+}
+
+// WithUsedOTPStore enables you to replace the in-memory store VerifyOTPOnce records verified OTPs in
+func WithUsedOTPStore(store UsedOTPStore) func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.usedOTPs = store
+	}
+}
+
+// VerifyOTPOnce is like VerifyOTPContext but returns ErrOTPAlreadyUsed when the otp was already
+// verified successfully for the userid. Verified pairs are remembered for the remaining lifetime
+// of the OTP if this Textbelt generated it, see VerifyOTPResult.RemainingLifetime, and otherwise
+// for 24 hours, the longest lifetime textbelt allows, so a pair can not be reused while it might
+// still be valid.
+func (t *Textbelt) VerifyOTPOnce(ctx context.Context, otp, userid string) (bool, error) {
+	res, err := t.VerifyOTPWithResultContext(ctx, otp, userid)
+	if err != nil || !res.Valid {
+		return false, err
+	}
+
+	ttl := res.RemainingLifetime
+	if ttl <= 0 {
+		ttl = maxOTPLifetime * time.Second
+	}
+
+	used, err := t.usedOTPs.MarkUsed(ctx, otp, userid, ttl)
+	if err != nil {
+		return false, err
+	}
+	if used {
+		return false, ErrOTPAlreadyUsed
+	}
+	return true, nil
+}
+
+// memoryOTPStore is the default UsedOTPStore keeping the pairs in memory of this Textbelt
+type memoryOTPStore struct {
+	now     func() time.Time
+	mu      sync.Mutex
+	expires map[[2]string]time.Time
+}
+
+func newMemoryOTPStore(now func() time.Time) *memoryOTPStore {
+	return &memoryOTPStore{
+		now:     now,
+		expires: make(map[[2]string]time.Time),
+	}
+}
+
+func (s *memoryOTPStore) MarkUsed(_ context.Context, otp, userid string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for k, exp := range s.expires {
+		if !now.Before(exp) {
+			delete(s.expires, k)
+		}
+	}
+
+	k := [2]string{otp, userid}
+	if _, ok := s.expires[k]; ok {
+		return true, nil
+	}
+	s.expires[k] = now.Add(ttl)
+	return false, nil
+}
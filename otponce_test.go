@@ -0,0 +1,96 @@
+package textbelt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// ttlStore records the ttl of every MarkUsed call
+type ttlStore struct {
+	ttls []time.Duration
+}
+
+func (s *ttlStore) MarkUsed(_ context.Context, _, _ string, ttl time.Duration) (bool, error) {
+	s.ttls = append(s.ttls, ttl)
+	return false, nil
+}
+
+func TestVerifyOTPOnceTTL(t *testing.T) {
+	srv := newFakeServer(t)
+	store := &ttlStore{}
+	now := time.Now()
+	texter := New(WithURL(srv.URL), WithUsedOTPStore(store), WithClock(func() time.Time { return now }))
+
+	otp, err := texter.GenerateCustomOTP(&CustomOTP{Phone: "+15555555555", UserID: "u1", Lifetime: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(20 * time.Second)
+	if valid, err := texter.VerifyOTPOnce(context.Background(), otp, "u1"); err != nil || !valid {
+		t.Fatalf("VerifyOTPOnce = %v, %v, want valid", valid, err)
+	}
+
+	// an OTP generated elsewhere has no known lifetime
+	srv.SetResponse("/otp/verify", http.StatusOK, `{"success":true,"isValidOtp":true}`)
+	if valid, err := texter.VerifyOTPOnce(context.Background(), "123456", "u2"); err != nil || !valid {
+		t.Fatalf("VerifyOTPOnce = %v, %v, want valid", valid, err)
+	}
+
+	want := []time.Duration{40 * time.Second, maxOTPLifetime * time.Second}
+	if len(store.ttls) != len(want) || store.ttls[0] != want[0] || store.ttls[1] != want[1] {
+		t.Errorf("marked used for %v, want %v", store.ttls, want)
+	}
+}
+
+func TestVerifyOTPOnce(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.SetResponse("/otp/verify", http.StatusOK, `{"success":true,"isValidOtp":true}`)
+	texter := New(WithURL(srv.URL))
+	ctx := context.Background()
+
+	if valid, err := texter.VerifyOTPOnce(ctx, "123456", "u1"); err != nil || !valid {
+		t.Fatalf("first VerifyOTPOnce = %v, %v, want valid", valid, err)
+	}
+	if valid, err := texter.VerifyOTPOnce(ctx, "123456", "u1"); !errors.Is(err, ErrOTPAlreadyUsed) || valid {
+		t.Errorf("second VerifyOTPOnce = %v, %v, want ErrOTPAlreadyUsed", valid, err)
+	}
+	if valid, err := texter.VerifyOTPOnce(ctx, "123456", "u2"); err != nil || !valid {
+		t.Errorf("VerifyOTPOnce for another userid = %v, %v, want valid", valid, err)
+	}
+
+	// invalid OTPs are not remembered
+	srv.SetResponse("/otp/verify", http.StatusOK, `{"success":true,"isValidOtp":false}`)
+	if valid, err := texter.VerifyOTPOnce(ctx, "654321", "u1"); err != nil || valid {
+		t.Errorf("wrong OTP = %v, %v, want invalid", valid, err)
+	}
+	srv.SetResponse("/otp/verify", http.StatusOK, `{"success":true,"isValidOtp":true}`)
+	if valid, err := texter.VerifyOTPOnce(ctx, "654321", "u1"); err != nil || !valid {
+		t.Errorf("OTP after a failed verification = %v, %v, want valid", valid, err)
+	}
+}
+
+func TestRemainingLifetime(t *testing.T) {
+	srv := newFakeServer(t)
+	now := time.Now()
+	texter := New(WithURL(srv.URL), WithClock(func() time.Time { return now }))
+
+	if _, err := texter.GenerateOTP("+15555555555", "u1"); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Minute)
+	res, err := texter.VerifyOTPWithResult("000000", "u1")
+	if err != nil || res.RemainingLifetime != defaultOTPLifetime-time.Minute {
+		t.Errorf("VerifyOTPWithResult = %+v, %v, want %v left", res, err, defaultOTPLifetime-time.Minute)
+	}
+
+	now = now.Add(defaultOTPLifetime)
+	if res, err := texter.VerifyOTPWithResult("000000", "u1"); err != nil || res.RemainingLifetime != 0 {
+		t.Errorf("VerifyOTPWithResult after expiry = %+v, %v, want none left", res, err)
+	}
+	if res, err := texter.VerifyOTPWithResult("000000", "unknown"); err != nil || res.RemainingLifetime != 0 {
+		t.Errorf("VerifyOTPWithResult of an unknown userid = %+v, %v, want none left", res, err)
+	}
+}
@@ -0,0 +1,52 @@
+package textbelt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		raw, region string
+		want        string
+		wantErr     bool
+	}{
+		{raw: "+1 (555) 555-5555", region: "", want: "+15555555555"},
+		{raw: "0049 30 1234567", region: "US", want: "+49301234567"},
+		{raw: "030 1234567", region: "DE", want: "+49301234567"},
+		{raw: "06 1234 5678", region: "it", want: "+390612345678"},
+		{raw: "15555555555", region: "US", want: "+15555555555"},
+		{raw: "", region: "DE", wantErr: true},
+		{raw: " - ", region: "DE", wantErr: true},
+		{raw: "0", region: "DE", wantErr: true},
+		{raw: "+49", region: "", wantErr: true},
+		{raw: "011", region: "US", wantErr: true},
+		{raw: "12345", region: "XX", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizePhone(tt.raw, tt.region)
+		if tt.wantErr {
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Errorf("NormalizePhone(%q, %q) = %q, %v, want a ValidationError", tt.raw, tt.region, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizePhone(%q, %q) = %q, %v, want %q", tt.raw, tt.region, got, err, tt.want)
+		}
+	}
+}
+
+func TestSendEmptyPhoneWithDefaultRegion(t *testing.T) {
+	srv := newFakeServer(t)
+
+	texter := New(WithURL(srv.URL), WithDefaultRegion("DE"), WithPhoneValidation(true))
+	if _, err := texter.Send("", "test message"); err == nil {
+		t.Error("Send with an empty phone succeeded")
+	}
+	if n := len(srv.Requests()); n != 0 {
+		t.Errorf("%d requests made, want 0", n)
+	}
+}
@@ -0,0 +1,45 @@
+package textbelt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ErrUnavailable is returned by Ping when textbelt cannot be reached
+var ErrUnavailable = errors.New("textbelt: service unavailable")
+
+// Ping checks that textbelt is reachable and accepts the key by asking for its quota.
+// Connectivity failures and 5xx responses match ErrUnavailable, a rejected key matches ErrInvalidKey.
+func (t *Textbelt) Ping(ctx context.Context) error {
+	key, err := t.apiKey()
+	if err != nil {
+		return err
+	}
+
+	_, err = t.doChecked(ctx, "Ping", http.MethodGet, quotaPath(key), nil, checkPing)
+	if err != nil {
+		var uerr *url.Error
+		var herr *HTTPError
+		if errors.As(err, &uerr) || errors.As(err, &herr) && herr.StatusCode >= 500 {
+			return fmt.Errorf("%w: %w", ErrUnavailable, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// checkPing returns the APIError of a failed quota lookup, which only fails because of the key
+func checkPing(r *response) error {
+	if r.Success {
+		return nil
+	}
+
+	aerr := apiError(r.Error)
+	if aerr.Err == nil {
+		aerr.Err = ErrInvalidKey
+	}
+	return aerr
+}
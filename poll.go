@@ -0,0 +1,160 @@
+package textbelt
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// WaitForDelivery polls Status every pollInterval until the message is DELIVERED or FAILED.
+// If ctx is done first, the last observed status is returned together with ctx.Err().
+// No goroutine is started and the timer is stopped on every return.
+func (t *Textbelt) WaitForDelivery(ctx context.Context, id string, pollInterval time.Duration) (MessageStatus, error) {
+	res, err := t.WaitForDeliveryWithResult(ctx, id, pollInterval)
+	return res.Status, err
+}
+
+// DeliveryResult holds the outcome of WaitForDeliveryWithResult
+type DeliveryResult struct {
+	Status MessageStatus // Last observed status of the message
+
+	// DeliveredAt is when DELIVERED was first observed, zero otherwise. textbelt does not report
+	// the delivery time, so this is the time of the poll which saw it, up to pollInterval plus the
+	// request duration after the actual delivery.
+	DeliveredAt time.Time
+}
+
+// WaitForDeliveryWithResult is like WaitForDelivery but also reports when the delivery was observed.
+// The result is never nil.
+func (t *Textbelt) WaitForDeliveryWithResult(ctx context.Context, id string, pollInterval time.Duration) (*DeliveryResult, error) {
+	if pollInterval <= 0 {
+		return &DeliveryResult{Status: StatusUnknown}, &ValidationError{
+			Field:  "pollInterval",
+			Reason: "must be positive",
+		}
+	}
+	return t.WaitForDeliveryWithStrategy(ctx, id, LinearPoll{Interval: pollInterval})
+}
+
+// PollStrategy computes how long to wait between Status calls while waiting for a delivery
+type PollStrategy interface {
+	// NextInterval returns the wait after the poll, counted from 1, which saw a pending status
+	NextInterval(poll int) time.Duration
+}
+
+// LinearPoll waits Interval after the first poll and Step longer after every following one,
+// capped at Max if positive. Zero Step polls at a fixed interval.
+type LinearPoll struct {
+	Interval time.Duration
+	Step     time.Duration
+	Max      time.Duration
+}
+
+// NextInterval returns the wait after the poll
+func (p LinearPoll) NextInterval(poll int) time.Duration {
+	d := p.Interval + time.Duration(max(poll-1, 0))*p.Step
+	if p.Max > 0 && d > p.Max {
+		d = p.Max
+	}
+	return d
+}
+
+// ExponentialPoll waits Initial after the first poll and multiplies the wait by Factor, 2 if not
+// greater than 1, after every following one, capped at Max if positive
+type ExponentialPoll struct {
+	Initial time.Duration
+	Factor  float64
+	Max     time.Duration
+}
+
+// NextInterval returns the wait after the poll
+func (p ExponentialPoll) NextInterval(poll int) time.Duration {
+	factor := p.Factor
+	if factor <= 1 {
+		factor = 2
+	}
+
+	d := float64(p.Initial)
+	for i := 1; i < poll; i++ {
+		d *= factor
+		if p.Max > 0 && d >= float64(p.Max) || d >= math.MaxInt64/factor {
+			break
+		}
+	}
+
+	if p.Max > 0 && d > float64(p.Max) {
+		return p.Max
+	}
+	return time.Duration(d)
+}
+
+// WaitForDeliveryWithStrategy is like WaitForDeliveryWithResult but waits between polls as told
+// by strategy, e.g. ExponentialPoll to poll quickly at first and slow down for late deliveries.
+// The deadline of ctx bounds the whole wait. A nil strategy is a ValidationError without any poll,
+// a non-positive interval stops the wait with a ValidationError.
+func (t *Textbelt) WaitForDeliveryWithStrategy(ctx context.Context, id string, strategy PollStrategy) (*DeliveryResult, error) {
+	res := &DeliveryResult{Status: StatusUnknown}
+	if strategy == nil {
+		return res, &ValidationError{
+			Field:  "strategy",
+			Reason: "is nil",
+		}
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for poll := 1; ; poll++ {
+		select {
+		case <-ctx.Done():
+			return res, ctx.Err()
+		case <-timer.C:
+		}
+
+		status, err := t.StatusContext(ctx, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return res, ctx.Err()
+			}
+			return res, err
+		}
+		res.Status = status
+
+		if status.IsTerminal() {
+			if status == StatusDelivered {
+				res.DeliveredAt = t.now()
+			}
+			return res, nil
+		}
+
+		d := strategy.NextInterval(poll)
+		if d <= 0 {
+			return res, &ValidationError{
+				Field:  "pollInterval",
+				Reason: "must be positive",
+			}
+		}
+		timer.Reset(d)
+	}
+}
+
+// SendAndConfirm sends the message once and waits for it to be DELIVERED or FAILED, polling Status
+// every pollInterval. A failed delivery is reported as StatusFailed with a nil error, while ctx
+// expiring first returns the last observed status together with ctx.Err(). The ID of the message
+// is returned whenever it was sent. A non-positive pollInterval is a ValidationError and nothing is sent.
+func (t *Textbelt) SendAndConfirm(ctx context.Context, phone, content string, pollInterval time.Duration) (MessageStatus, string, error) {
+	if pollInterval <= 0 {
+		return "", "", &ValidationError{
+			Field:  "pollInterval",
+			Reason: "must be positive",
+		}
+	}
+
+	id, err := t.SendContext(ctx, phone, content)
+	if err != nil {
+		return "", "", err
+	}
+
+	status, err := t.WaitForDelivery(ctx, id, pollInterval)
+	return status, id, err
+}
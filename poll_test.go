@@ -0,0 +1,156 @@
+package textbelt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lateralusd/textbelt/textbelttest"
+)
+
+func TestSendAndConfirm(t *testing.T) {
+	var polls, sends atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/status/") {
+			status := StatusSending
+			if polls.Add(1) >= 3 {
+				status = StatusDelivered
+			}
+			fmt.Fprintf(w, `{"status":%q}`, status)
+			return
+		}
+		sends.Add(1)
+		w.Write([]byte(`{"success":true,"textId":"42"}`))
+	}))
+	defer srv.Close()
+
+	texter := New(WithURL(srv.URL))
+	status, id, err := texter.SendAndConfirm(context.Background(), "+15555555555", "test message", time.Millisecond)
+	if err != nil || status != StatusDelivered || id != "42" {
+		t.Errorf("SendAndConfirm returned %v, %q, %v, want DELIVERED, 42", status, id, err)
+	}
+	if polls.Load() != 3 {
+		t.Errorf("got %d polls, want 3", polls.Load())
+	}
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		_, _, err := texter.SendAndConfirm(context.Background(), "+15555555555", "test message", interval)
+		var verr *ValidationError
+		if !errors.As(err, &verr) || verr.Field != "pollInterval" {
+			t.Errorf("SendAndConfirm with interval %v returned %v, want a pollInterval ValidationError", interval, err)
+		}
+	}
+	if sends.Load() != 1 {
+		t.Errorf("got %d sends, want the invalid intervals to send nothing", sends.Load())
+	}
+}
+
+func TestWaitForDeliveryCancel(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	srv := textbelttest.NewFakeServer()
+	srv.SetResponse("/status", http.StatusOK, `{"success":true,"status":"SENT"}`)
+	texter := New(WithURL(srv.URL))
+
+	waits := map[string]func(ctx context.Context, interval time.Duration) (MessageStatus, error){
+		"WaitForDelivery": func(ctx context.Context, interval time.Duration) (MessageStatus, error) {
+			return texter.WaitForDelivery(ctx, "1", interval)
+		},
+		"SendAndConfirm": func(ctx context.Context, interval time.Duration) (MessageStatus, error) {
+			status, _, err := texter.SendAndConfirm(ctx, "+15555555555", "test message", interval)
+			return status, err
+		},
+	}
+
+	for name, wait := range waits {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		status, err := wait(ctx, 10*time.Millisecond)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: err = %v, want context.Canceled", name, err)
+		}
+		if status != StatusSent {
+			t.Errorf("%s: status = %v, want the last observed %v", name, status, StatusSent)
+		}
+
+		// a non-positive interval fails before any request
+		requests := len(srv.Requests())
+		for _, interval := range []time.Duration{0, -time.Second} {
+			var verr *ValidationError
+			if _, err := wait(context.Background(), interval); !errors.As(err, &verr) || verr.Field != "pollInterval" {
+				t.Errorf("%s with interval %v: err = %v, want a pollInterval ValidationError", name, interval, err)
+			}
+		}
+		if n := len(srv.Requests()) - requests; n != 0 {
+			t.Errorf("%s: %d requests made with non-positive intervals, want 0", name, n)
+		}
+	}
+
+	texter.Close()
+	srv.Close()
+
+	// connection goroutines of the server and the transport take a moment to exit
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		buf := make([]byte, 1<<16)
+		t.Errorf("%d goroutines left, %d before:\n%s", n, before, buf[:runtime.Stack(buf, true)])
+	}
+}
+
+func TestPollStrategies(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		strategy PollStrategy
+		want     []time.Duration // intervals after polls 1, 2, ...
+	}{
+		{name: "fixed", strategy: LinearPoll{Interval: time.Second}, want: []time.Duration{time.Second, time.Second, time.Second}},
+		{name: "linear", strategy: LinearPoll{Interval: time.Second, Step: time.Second, Max: 3 * time.Second}, want: []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}},
+		{name: "exponential", strategy: ExponentialPoll{Initial: time.Second, Factor: 3, Max: 20 * time.Second}, want: []time.Duration{time.Second, 3 * time.Second, 9 * time.Second, 20 * time.Second}},
+		{name: "exponential default factor", strategy: ExponentialPoll{Initial: time.Second}, want: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}},
+	} {
+		for i, want := range tt.want {
+			if got := tt.strategy.NextInterval(i + 1); got != want {
+				t.Errorf("%s: NextInterval(%d) = %v, want %v", tt.name, i+1, got, want)
+			}
+		}
+	}
+
+	// uncapped exponential polling must not overflow
+	if d := (ExponentialPoll{Initial: time.Hour}).NextInterval(1000); d <= 0 {
+		t.Errorf("uncapped NextInterval(1000) = %v, want a positive interval", d)
+	}
+}
+
+func TestWaitForDeliveryWithStrategyInvalid(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.SetResponse("/status", http.StatusOK, `{"success":true,"status":"SENT"}`)
+	texter := New(WithURL(srv.URL))
+
+	var verr *ValidationError
+	res, err := texter.WaitForDeliveryWithStrategy(context.Background(), "1", nil)
+	if !errors.As(err, &verr) || verr.Field != "strategy" || res == nil {
+		t.Errorf("nil strategy returned %+v, %v, want a strategy ValidationError", res, err)
+	}
+	if n := len(srv.Requests()); n != 0 {
+		t.Errorf("nil strategy made %d requests, want 0", n)
+	}
+
+	res, err = texter.WaitForDeliveryWithStrategy(context.Background(), "1", LinearPoll{})
+	if !errors.As(err, &verr) || verr.Field != "pollInterval" || res.Status != StatusSent {
+		t.Errorf("zero interval returned %+v, %v, want a pollInterval ValidationError after the first poll", res, err)
+	}
+	if n := len(srv.Requests()); n != 1 {
+		t.Errorf("zero interval made %d requests, want 1", n)
+	}
+}
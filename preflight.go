@@ -0,0 +1,78 @@
+package textbelt
+
+// Encoding is the character encoding an SMS is sent in
+type Encoding string
+
+const (
+	// EncodingGSM7 packs up to 160 characters of the GSM 03.38 alphabet into a single message
+	EncodingGSM7 Encoding = "GSM-7"
+	// EncodingUCS2 is used once content has a character outside of GSM-7, e.g. an emoji, and
+	// fits 70 UTF-16 units into a single message
+	EncodingUCS2 Encoding = "UCS-2"
+)
+
+// Preflight holds what PreflightCheck found out about a message without sending it
+type Preflight struct {
+	// Phone is the phone number as it would be sent, converted to E.164 format if
+	// WithDefaultRegion is set
+	Phone string
+
+	// PhoneValid reports whether Phone is in E.164 format, e.g. +15555555555. National numbers
+	// are reported as invalid even though textbelt may accept them, see WithDefaultRegion.
+	PhoneValid bool
+
+	// Region is the ISO 3166-1 alpha-2 region of Phone, empty if PhoneValid is false.
+	// Numbers of the North American Numbering Plan are all reported as US.
+	Region string
+
+	// Encoding is the encoding the content requires
+	Encoding Encoding
+
+	// Units is the length of the content in Encoding, GSM-7 septets or UTF-16 units. GSM-7
+	// extension characters such as € and emoji outside of the basic plane count twice.
+	Units int
+
+	// Segments is the number of SMS the content is split into, see SegmentCount
+	Segments int
+
+	// Cost is the estimated number of quota credits sending the message uses, see EstimateCost
+	Cost int
+}
+
+// PreflightCheck inspects sending content to phone without making a request, e.g. to show
+// the details in a review step before the message is sent. The Preflight is always returned,
+// the error reports the problems the local checks of Send would reject the message for.
+func (t *Textbelt) PreflightCheck(phone, content string) (*Preflight, error) {
+	v := t.newValidation()
+	phone = t.validatePhoneNumber(v, phone)
+	if !v.stopped() {
+		v.add(t.checkContent("message", content))
+	}
+
+	sizes, gsm := units(content)
+	p := &Preflight{
+		Phone:      phone,
+		PhoneValid: e164.MatchString(phone),
+		Encoding:   EncodingUCS2,
+		Segments:   SegmentCount(content),
+	}
+	if gsm {
+		p.Encoding = EncodingGSM7
+	}
+	for _, n := range sizes {
+		p.Units += n
+	}
+
+	if p.PhoneValid {
+		_, p.Region, _ = phoneRegion(phone)
+	}
+
+	cost, err := t.EstimateCost(phone, content)
+	if err != nil {
+		// the phone problem is reported through PhoneValid, so assume no country multiplier
+		cost = p.Segments * t.costPerSegment
+	}
+	p.Cost = cost
+
+	return p, v.err()
+}
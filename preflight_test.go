@@ -0,0 +1,39 @@
+package textbelt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPreflightCheck(t *testing.T) {
+	texter := New(WithURL("http://127.0.0.1:1"), WithCountryMultipliers(map[string]int{"GB": 3}))
+
+	tests := []struct {
+		phone, content string
+		want           Preflight
+	}{
+		{
+			phone: "+447700900123", content: "price: 5€",
+			want: Preflight{Phone: "+447700900123", PhoneValid: true, Region: "GB", Encoding: EncodingGSM7, Units: 10, Segments: 1, Cost: 3},
+		},
+		{
+			phone: "+15555555555", content: "hi 😀",
+			want: Preflight{Phone: "+15555555555", PhoneValid: true, Region: "US", Encoding: EncodingUCS2, Units: 5, Segments: 1, Cost: 1},
+		},
+		{
+			phone: "5555555", content: strings.Repeat("a", 161),
+			want: Preflight{Phone: "5555555", Encoding: EncodingGSM7, Units: 161, Segments: 2, Cost: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := texter.PreflightCheck(tt.phone, tt.content)
+		if err != nil {
+			t.Errorf("PreflightCheck(%q, %q): %v", tt.phone, tt.content, err)
+			continue
+		}
+		if *got != tt.want {
+			t.Errorf("PreflightCheck(%q, %q) = %+v, want %+v", tt.phone, tt.content, *got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package textbelt
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// quotaCache holds the last known quota, it is safe for concurrent use
+type quotaCache struct {
+	ttl    time.Duration
+	pinned bool // the value is kept fresh by the background refresh and never expires
+
+	mu        sync.Mutex
+	remaining int
+	updated   time.Time
+}
+
+// WithQuotaCache enables you to cache the result of Quota for ttl. The cache is also
+// updated from the quota remaining reported by sent messages and generated OTPs.
+func WithQuotaCache(ttl time.Duration) func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.quota = &quotaCache{ttl: ttl}
+	}
+}
+
+// get returns the cached quota if it is not older than the ttl
+func (c *quotaCache) get(now time.Time) (int, bool) {
+	if c == nil {
+		return 0, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.updated.IsZero() || !c.pinned && now.Sub(c.updated) > c.ttl {
+		return 0, false
+	}
+	return c.remaining, true
+}
+
+func (c *quotaCache) set(remaining int, now time.Time) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.remaining = remaining
+	c.updated = now
+}
+
+// quotaWatermark calls its callback once the quota drops to or below the threshold,
+// it is safe for concurrent use
+type quotaWatermark struct {
+	threshold int
+	cb        func(remaining int)
+
+	mu    sync.Mutex
+	below bool
+}
+
+// WithQuotaWatermark enables you to get notified when the quota reported by textbelt drops to or
+// below threshold. cb runs in its own goroutine once per crossing, it is not called again until
+// the quota rose above threshold, e.g. after a top-up.
+func WithQuotaWatermark(threshold int, cb func(remaining int)) func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.watermark = &quotaWatermark{
+			threshold: threshold,
+			cb:        cb,
+		}
+	}
+}
+
+func (w *quotaWatermark) observe(remaining int) {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	crossed := remaining <= w.threshold && !w.below
+	w.below = remaining <= w.threshold
+	w.mu.Unlock()
+
+	if crossed && w.cb != nil {
+		go w.cb(remaining)
+	}
+}
+
+// setQuota records the quota reported by textbelt, nil if the response did not report it
+func (t *Textbelt) setQuota(remaining *int) {
+	if remaining == nil {
+		return
+	}
+	t.quota.set(*remaining, t.now())
+	t.watermark.observe(*remaining)
+}
+
+// QuotaFor returns the remaining quota of an arbitrary key without changing the configured one.
+// It always asks textbelt, the quota cache is neither used nor updated.
+func (t *Textbelt) QuotaFor(ctx context.Context, key string) (int, error) {
+	r, err := t.do(ctx, "Quota", http.MethodGet, quotaPath(key), nil)
+	if err != nil {
+		return -1, err
+	}
+	if r.QuotaRemaining == nil {
+		return -1, ErrQuotaUnavailable
+	}
+	return *r.QuotaRemaining, nil
+}
+
+// WithBackgroundQuotaRefresh enables you to refresh the quota every interval in a background goroutine,
+// so Quota returns the last refreshed value without blocking on a request. The goroutine is started
+// by New and stopped by Close.
+func WithBackgroundQuotaRefresh(interval time.Duration) func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.quotaRefresh = interval
+	}
+}
+
+// LastQuota returns the last known quota and when it was fetched, ok is false if it is not known yet.
+// It requires WithQuotaCache or WithBackgroundQuotaRefresh and never makes a request.
+func (t *Textbelt) LastQuota() (remaining int, updated time.Time, ok bool) {
+	if t.quota == nil {
+		return 0, time.Time{}, false
+	}
+
+	t.quota.mu.Lock()
+	defer t.quota.mu.Unlock()
+
+	return t.quota.remaining, t.quota.updated, !t.quota.updated.IsZero()
+}
+
+// startQuotaRefresh starts the goroutine refreshing the quota until Close is called
+func (t *Textbelt) startQuotaRefresh() {
+	if t.quota == nil {
+		t.quota = &quotaCache{}
+	}
+	t.quota.pinned = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.stop = cancel
+	t.done = make(chan struct{})
+
+	go func() {
+		defer close(t.done)
+
+		ticker := time.NewTicker(t.quotaRefresh)
+		defer ticker.Stop()
+
+		for {
+			// failures keep the previous value, the next tick tries again
+			_, _ = t.QuotaFreshContext(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
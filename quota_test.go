@@ -0,0 +1,107 @@
+package textbelt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuotaCache(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.SetQuota("key", 10)
+	quotaRequests := func() int {
+		n := 0
+		for _, req := range srv.Requests() {
+			if req.Path == "/quota/key" {
+				n++
+			}
+		}
+		return n
+	}
+
+	now := time.Now()
+	texter := New(
+		WithURL(srv.URL),
+		WithKey("key"),
+		WithQuotaCache(time.Minute),
+		WithClock(func() time.Time { return now }),
+	)
+
+	for range 2 {
+		if remaining, err := texter.Quota(); err != nil || remaining != 10 {
+			t.Fatalf("Quota = %d, %v, want 10", remaining, err)
+		}
+	}
+	if n := quotaRequests(); n != 1 {
+		t.Errorf("made %d quota requests, want the second Quota to be cached", n)
+	}
+
+	// sends and OTPs report the quota too
+	if _, err := texter.Send("+15555555555", "test message"); err != nil {
+		t.Fatal(err)
+	}
+	if remaining, err := texter.Quota(); err != nil || remaining != 9 {
+		t.Errorf("Quota after a send = %d, %v, want 9", remaining, err)
+	}
+	if _, err := texter.GenerateOTP("+15555555555", "u1"); err != nil {
+		t.Fatal(err)
+	}
+	if remaining, err := texter.Quota(); err != nil || remaining != 8 {
+		t.Errorf("Quota after an OTP = %d, %v, want 8", remaining, err)
+	}
+	if n := quotaRequests(); n != 1 {
+		t.Errorf("made %d quota requests, want the reported quota to be cached", n)
+	}
+
+	srv.SetQuota("key", 5)
+	now = now.Add(time.Minute + time.Second)
+	if remaining, err := texter.Quota(); err != nil || remaining != 5 {
+		t.Errorf("Quota after the TTL = %d, %v, want 5", remaining, err)
+	}
+	srv.SetQuota("key", 4)
+	if remaining, err := texter.QuotaFresh(); err != nil || remaining != 4 {
+		t.Errorf("QuotaFresh = %d, %v, want 4", remaining, err)
+	}
+	if n := quotaRequests(); n != 3 {
+		t.Errorf("made %d quota requests, want 3", n)
+	}
+}
+
+func TestQuotaWatermark(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.SetQuota("key", 4)
+
+	calls := make(chan int, 10)
+	texter := New(WithURL(srv.URL), WithKey("key"), WithQuotaWatermark(2, func(remaining int) {
+		calls <- remaining
+	}))
+	send := func() {
+		t.Helper()
+		if _, err := texter.Send("+15555555555", "test message"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	send() // 3
+	send() // 2, crossing
+	send() // 1
+	if got := <-calls; got != 2 {
+		t.Errorf("callback got %d, want 2", got)
+	}
+
+	// a top-up arms the watermark again
+	srv.SetQuota("key", 10)
+	if _, err := texter.QuotaFresh(); err != nil {
+		t.Fatal(err)
+	}
+	srv.SetQuota("key", 3)
+	send() // 2, crossing
+	if got := <-calls; got != 2 {
+		t.Errorf("callback after the top-up got %d, want 2", got)
+	}
+
+	select {
+	case got := <-calls:
+		t.Errorf("callback called again with %d", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,140 @@
+package textbelt
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Backoff computes how long to wait before the next attempt of a failed request
+type Backoff interface {
+	// NextDelay returns the delay after the attempt, counted from 1, failed
+	NextDelay(attempt int) time.Duration
+}
+
+// BackoffFunc enables you to use an ordinary function as Backoff
+type BackoffFunc func(attempt int) time.Duration
+
+// NextDelay calls f(attempt)
+func (f BackoffFunc) NextDelay(attempt int) time.Duration {
+	return f(attempt)
+}
+
+// ExponentialBackoff doubles the delay after every attempt starting from BaseDelay, with random
+// jitter of up to half the delay. MaxDelay caps the delay if positive.
+type ExponentialBackoff struct {
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// maxBackoffShift caps the exponent so the delay does not overflow
+const maxBackoffShift = 16
+
+// NextDelay returns the delay after the attempt failed
+func (b ExponentialBackoff) NextDelay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	if attempt > maxBackoffShift {
+		attempt = maxBackoffShift
+	}
+
+	d := b.BaseDelay << (attempt - 1)
+	if b.MaxDelay > 0 && d > b.MaxDelay {
+		d = b.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	// full delay halved plus random jitter up to the other half
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// ConstantBackoff waits the same Delay after every attempt
+type ConstantBackoff struct {
+	Delay time.Duration
+}
+
+// NextDelay returns Delay
+func (b ConstantBackoff) NextDelay(int) time.Duration {
+	return b.Delay
+}
+
+// WithRetry enables you to retry requests failing with network errors, 429 or 5xx responses.
+// Requests are made at most maxAttempts times waiting for the delay returned by backoff between
+// attempts, e.g. ExponentialBackoff. Retry-After sent by textbelt is used instead of the delay when
+// present. The wait ends early when the context of the request is done.
+func WithRetry(maxAttempts int, backoff Backoff) func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.retries = maxAttempts
+		t.retryBackoff = backoff
+	}
+}
+
+// WithOperationTimeout enables you to bound every request including all its retries, rate limiter
+// waits and backoff delays to d. WithTimeout still limits each single attempt, so without this
+// option a request retried with WithRetry may take up to maxAttempts times the timeout plus the
+// delays. The operation fails with context.DeadlineExceeded once d passed, whichever of d and the
+// deadline of the context passed to the Context methods is earlier wins.
+func WithOperationTimeout(d time.Duration) func(*Textbelt) {
+	return func(t *Textbelt) {
+		if d <= 0 {
+			t.setErr(&ValidationError{
+				Field:  "operation timeout",
+				Reason: "must be positive",
+			})
+			return
+		}
+		t.operationTimeout = d
+	}
+}
+
+// retryable reports whether the request failing with err should be retried
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var herr *HTTPError
+	if errors.As(err, &herr) {
+		return herr.StatusCode == http.StatusTooManyRequests || herr.StatusCode >= 500
+	}
+
+	var aerr *APIError
+	if errors.As(err, &aerr) {
+		return false
+	}
+
+	// decoding errors are not worth retrying, only transport ones
+	var uerr *url.Error
+	return errors.As(err, &uerr)
+}
+
+// backoff returns how long to wait after the attempt failed with err
+func (t *Textbelt) backoff(attempt int, err error) time.Duration {
+	var herr *HTTPError
+	if errors.As(err, &herr) && herr.RetryAfter > 0 {
+		return herr.RetryAfter
+	}
+
+	if t.retryBackoff == nil {
+		return 0
+	}
+	return t.retryBackoff.NextDelay(attempt)
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,190 @@
+package textbelt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	b := ExponentialBackoff{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for attempt, full := range map[int]time.Duration{
+		0:  100 * time.Millisecond,
+		1:  100 * time.Millisecond,
+		2:  200 * time.Millisecond,
+		3:  400 * time.Millisecond,
+		4:  800 * time.Millisecond,
+		5:  time.Second,
+		64: time.Second,
+	} {
+		for range 20 {
+			if d := b.NextDelay(attempt); d < full/2 || d > full {
+				t.Errorf("NextDelay(%d) = %v, want between %v and %v", attempt, d, full/2, full)
+			}
+		}
+	}
+
+	// without MaxDelay large attempts must not overflow
+	uncapped := ExponentialBackoff{BaseDelay: time.Hour}
+	if d := uncapped.NextDelay(1000); d <= 0 {
+		t.Errorf("uncapped NextDelay(1000) = %v, want a positive delay", d)
+	}
+	if d := (ExponentialBackoff{}).NextDelay(3); d != 0 {
+		t.Errorf("zero ExponentialBackoff NextDelay = %v, want 0", d)
+	}
+}
+
+func TestConstantBackoff(t *testing.T) {
+	b := ConstantBackoff{Delay: 50 * time.Millisecond}
+	for attempt := 1; attempt < 5; attempt++ {
+		if d := b.NextDelay(attempt); d != 50*time.Millisecond {
+			t.Errorf("NextDelay(%d) = %v, want 50ms", attempt, d)
+		}
+	}
+
+	f := BackoffFunc(func(attempt int) time.Duration { return time.Duration(attempt) * time.Second })
+	if d := f.NextDelay(3); d != 3*time.Second {
+		t.Errorf("BackoffFunc NextDelay(3) = %v, want 3s", d)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	transport := &url.Error{Op: "Post", URL: "http://textbelt.test/text", Err: errors.New("connection refused")}
+
+	for _, tt := range []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "network error", err: transport, want: true},
+		{name: "wrapped network error", err: fmt.Errorf("sending: %w", transport), want: true},
+		{name: "429", err: &HTTPError{StatusCode: http.StatusTooManyRequests}, want: true},
+		{name: "500", err: &HTTPError{StatusCode: http.StatusInternalServerError}, want: true},
+		{name: "503", err: &HTTPError{StatusCode: http.StatusServiceUnavailable}, want: true},
+		{name: "400", err: &HTTPError{StatusCode: http.StatusBadRequest}},
+		{name: "api error", err: &APIError{Message: "Out of quota", Err: ErrQuotaExceeded}},
+		{name: "decode error", err: &DecodeError{Err: errors.New("invalid character")}},
+		{name: "canceled", err: &url.Error{Op: "Post", URL: "http://textbelt.test/text", Err: context.Canceled}},
+		{name: "deadline", err: context.DeadlineExceeded},
+	} {
+		if got := retryable(tt.err); got != tt.want {
+			t.Errorf("%s: retryable = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBackoffRetryAfter(t *testing.T) {
+	texter := New(WithRetry(3, ConstantBackoff{Delay: time.Second}))
+
+	if d := texter.backoff(1, &HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: 5 * time.Second}); d != 5*time.Second {
+		t.Errorf("backoff with Retry-After = %v, want 5s", d)
+	}
+	if d := texter.backoff(1, &HTTPError{StatusCode: http.StatusServiceUnavailable}); d != time.Second {
+		t.Errorf("backoff without Retry-After = %v, want the 1s of the Backoff", d)
+	}
+	if d := New().backoff(1, &HTTPError{StatusCode: http.StatusServiceUnavailable}); d != 0 {
+		t.Errorf("backoff without a Backoff = %v, want 0", d)
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	srv := newFakeServer(t)
+	sends := func() int {
+		n := 0
+		for _, req := range srv.Requests() {
+			if req.Path == "/text" {
+				n++
+			}
+		}
+		return n
+	}
+
+	var attempts []int
+	backoff := BackoffFunc(func(attempt int) time.Duration {
+		attempts = append(attempts, attempt)
+		return time.Millisecond
+	})
+	texter := New(WithURL(srv.URL), WithRetry(3, backoff))
+
+	srv.SetResponse("/text", http.StatusServiceUnavailable, "Service Unavailable")
+	var herr *HTTPError
+	if _, err := texter.Send("+15555555555", "test message"); !errors.As(err, &herr) || herr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Send against a failing server returned %v, want the last HTTPError", err)
+	}
+	if n := sends(); n != 3 {
+		t.Errorf("made %d attempts, want 3", n)
+	}
+	if fmt.Sprint(attempts) != "[1 2]" {
+		t.Errorf("Backoff asked after attempts %v, want [1 2]", attempts)
+	}
+
+	// errors which are not retried stop at once
+	srv.SetResponse("/text", http.StatusOK, `{"success":false,"error":"Invalid phone number"}`)
+	if _, err := texter.Send("+15555555555", "test message"); !errors.Is(err, ErrInvalidPhone) {
+		t.Errorf("Send returned %v, want ErrInvalidPhone", err)
+	}
+	if n := sends(); n != 4 {
+		t.Errorf("made %d attempts in total, want the API error to be tried once", n)
+	}
+
+	// a request succeeding on a later attempt returns its result
+	srv.SetResponse("/text", http.StatusBadGateway, "Bad Gateway")
+	recovering := New(WithURL(srv.URL), WithRetry(3, BackoffFunc(func(int) time.Duration {
+		srv.SetResponse("/text", http.StatusOK, "")
+		return 0
+	})))
+	if _, err := recovering.Send("+15555555555", "test message"); err != nil {
+		t.Errorf("Send recovering on the second attempt returned %v", err)
+	}
+	if n := sends(); n != 6 {
+		t.Errorf("made %d attempts in total, want 2 for the recovering send", n)
+	}
+}
+
+func TestWithRetryContextCanceled(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.SetResponse("/text", http.StatusServiceUnavailable, "Service Unavailable")
+	texter := New(WithURL(srv.URL), WithRetry(5, ConstantBackoff{Delay: time.Minute}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := texter.SendContext(ctx, "+15555555555", "test message"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SendContext returned %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("SendContext took %v, want the backoff to end with the context", elapsed)
+	}
+}
+
+func TestOperationTimeoutBoundsRetries(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.SetResponse("/text", http.StatusServiceUnavailable, "Service Unavailable")
+
+	const bound = 300 * time.Millisecond
+	texter := New(
+		WithURL(srv.URL),
+		WithRetry(3, ConstantBackoff{Delay: 200 * time.Millisecond}),
+		WithOperationTimeout(bound),
+	)
+
+	start := time.Now()
+	_, err := texter.Send("+15555555555", "test message")
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Send returned %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed > bound+200*time.Millisecond {
+		t.Errorf("Send took %v, want at most about %v", elapsed, bound)
+	}
+	if n := len(srv.Requests()); n != 2 {
+		t.Errorf("%d attempts made, want 2 before the operation timeout", n)
+	}
+}
@@ -0,0 +1,81 @@
+package textbelt
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	gsmSingle = 160 // septets in a single GSM-7 message
+	gsmPart   = 153 // septets in each part of a concatenated GSM-7 message
+	ucsSingle = 70  // UTF-16 units in a single UCS-2 message
+	ucsPart   = 67  // UTF-16 units in each part of a concatenated UCS-2 message
+)
+
+const (
+	gsmBasic     = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+	gsmExtension = "\f^{}\\[~]|€"
+)
+
+// SegmentCount returns the number of SMS segments content will be split into.
+// Content made only of GSM-7 characters uses 160 characters per message or 153 per segment
+// once it has to be split, anything else, emoji included, forces UCS-2 with 70 and 67.
+// Characters taking two units (GSM-7 extensions, UTF-16 surrogate pairs) are never split between segments.
+func SegmentCount(content string) int {
+	if content == "" {
+		return 0
+	}
+
+	sizes, gsm := units(content)
+	single, part := ucsSingle, ucsPart
+	if gsm {
+		single, part = gsmSingle, gsmPart
+	}
+
+	total := 0
+	for _, n := range sizes {
+		total += n
+	}
+	if total <= single {
+		return 1
+	}
+
+	segments, used := 1, 0
+	for _, n := range sizes {
+		if used+n > part {
+			segments++
+			used = 0
+		}
+		used += n
+	}
+	return segments
+}
+
+// units returns the size of every character of content in the encoding it requires
+// and whether that encoding is GSM-7
+func units(content string) ([]int, bool) {
+	sizes := make([]int, 0, len(content))
+	gsm := true
+	for _, r := range content {
+		switch {
+		case strings.ContainsRune(gsmBasic, r):
+			sizes = append(sizes, 1)
+		case strings.ContainsRune(gsmExtension, r):
+			sizes = append(sizes, 2)
+		default:
+			gsm = false
+			sizes = append(sizes, 0)
+		}
+	}
+
+	if gsm {
+		return sizes, true
+	}
+
+	i := 0
+	for _, r := range content {
+		sizes[i] = len(utf16.Encode([]rune{r}))
+		i++
+	}
+	return sizes, false
+}
@@ -0,0 +1,37 @@
+package textbelt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSegmentCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{name: "empty", content: "", want: 0},
+		{name: "gsm single", content: strings.Repeat("a", 160), want: 1},
+		{name: "gsm split", content: strings.Repeat("a", 161), want: 2},
+		{name: "gsm two full parts", content: strings.Repeat("a", 306), want: 2},
+		{name: "gsm third part", content: strings.Repeat("a", 307), want: 3},
+		{name: "gsm extension counts twice", content: strings.Repeat("€", 80), want: 1},
+		{name: "gsm extension over the limit", content: strings.Repeat("€", 80) + "a", want: 2},
+		{name: "gsm extension not split", content: strings.Repeat("a", 152) + "€" + strings.Repeat("a", 152), want: 3},
+		{name: "ucs single", content: strings.Repeat("ж", 70), want: 1},
+		{name: "ucs split", content: strings.Repeat("ж", 71), want: 2},
+		{name: "ucs two full parts", content: strings.Repeat("ж", 134), want: 2},
+		{name: "ucs third part", content: strings.Repeat("ж", 135), want: 3},
+		{name: "emoji forces ucs", content: strings.Repeat("a", 69) + "😀", want: 2},
+		{name: "emoji single", content: strings.Repeat("a", 68) + "😀", want: 1},
+		{name: "emoji not split", content: strings.Repeat("a", 66) + "😀" + strings.Repeat("a", 5), want: 2},
+		{name: "emoji straddling the boundary", content: strings.Repeat("a", 66) + "😀" + strings.Repeat("a", 66), want: 3},
+	}
+
+	for _, tt := range tests {
+		if got := SegmentCount(tt.content); got != tt.want {
+			t.Errorf("%s: SegmentCount = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package textbelt
+
+import "time"
+
+// SendOption customizes a single message sent with SendContext or SendWithResultContext.
+// Options are applied in order, so when the same option is passed twice the last one wins.
+type SendOption func(*Message)
+
+// WithSender sets the name of the sender shown to the receiver
+func WithSender(name string) SendOption {
+	return func(m *Message) {
+		m.Sender = name
+	}
+}
+
+// WithReplyWebhook sets the URL textbelt will POST replies from the receiver to
+func WithReplyWebhook(url string) SendOption {
+	return func(m *Message) {
+		m.ReplyWebhookURL = url
+	}
+}
+
+// WithWebhookData sets the data passed back with the reply webhook, at most 100 characters
+func WithWebhookData(data string) SendOption {
+	return func(m *Message) {
+		m.WebhookData = data
+	}
+}
+
+// WithStatusCallback sets the URL textbelt will POST delivery status updates to
+func WithStatusCallback(url string) SendOption {
+	return func(m *Message) {
+		m.StatusCallbackURL = url
+	}
+}
+
+// WithSendAt schedules the message to be delivered at when
+func WithSendAt(when time.Time) SendOption {
+	return func(m *Message) {
+		m.SendAt = when
+	}
+}
+
+// WithExtra sets an additional form field, see Message.Extra. Fields with different
+// names are merged, passing the same name twice keeps the last value.
+func WithExtra(name, value string) SendOption {
+	return func(m *Message) {
+		if m.Extra == nil {
+			m.Extra = make(map[string]string)
+		}
+		m.Extra[name] = value
+	}
+}
+
+// WithMediaURL sets the URL of an image to send as MMS, see SendMMS
+func WithMediaURL(url string) SendOption {
+	return func(m *Message) {
+		m.MediaURL = url
+	}
+}
@@ -0,0 +1,49 @@
+package textbelt
+
+import "strings"
+
+// ParseMessageStatus normalizes the casing of s and maps unknown statuses to StatusUnknown
+func ParseMessageStatus(s string) MessageStatus {
+	switch status := MessageStatus(strings.ToUpper(strings.TrimSpace(s))); status {
+	case StatusDelivered, StatusSent, StatusSending, StatusFailed:
+		return status
+	default:
+		return StatusUnknown
+	}
+}
+
+// IsTerminal reports whether the status will not change anymore
+func (s MessageStatus) IsTerminal() bool {
+	return s == StatusDelivered || s == StatusFailed
+}
+
+// IsSuccess reports whether the message was delivered
+func (s MessageStatus) IsSuccess() bool {
+	return s == StatusDelivered
+}
+
+// IsPending reports whether the message is still on its way
+func (s MessageStatus) IsPending() bool {
+	return s == StatusSent || s == StatusSending
+}
+
+// AllMessageStatuses returns every status a message can have, ending with StatusUnknown
+func AllMessageStatuses() []MessageStatus {
+	return []MessageStatus{StatusSending, StatusSent, StatusDelivered, StatusFailed, StatusUnknown}
+}
+
+// Description returns a human-readable description of the status
+func (s MessageStatus) Description() string {
+	switch s {
+	case StatusSending:
+		return "Message accepted and queued"
+	case StatusSent:
+		return "Message sent to the carrier"
+	case StatusDelivered:
+		return "Message delivered to the receiver"
+	case StatusFailed:
+		return "Message could not be delivered"
+	default:
+		return "Message state could not be determined"
+	}
+}
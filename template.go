@@ -0,0 +1,52 @@
+package textbelt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SendTemplate sends template with every {{name}} placeholder replaced by data[name].
+// It fails without sending if a placeholder is missing from data or its braces are not closed.
+// Nothing is escaped since SMS content is plain text.
+func (t *Textbelt) SendTemplate(ctx context.Context, phone, template string, data map[string]string, opts ...SendOption) (*SendResult, error) {
+	content, err := renderTemplate(template, data)
+	if err != nil {
+		return nil, err
+	}
+	return t.SendWithResultContext(ctx, phone, content, opts...)
+}
+
+// renderTemplate replaces {{name}} placeholders of template, spaces around the name are ignored
+func renderTemplate(template string, data map[string]string) (string, error) {
+	var b strings.Builder
+	rest := template
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			b.WriteString(rest)
+			return b.String(), nil
+		}
+
+		end := strings.Index(rest[start+2:], "}}")
+		if end < 0 {
+			return "", &ValidationError{
+				Field:  "template",
+				Reason: fmt.Sprintf("unclosed placeholder at byte offset %d", len(template)-len(rest)+start),
+			}
+		}
+
+		name := strings.TrimSpace(rest[start+2 : start+2+end])
+		value, ok := data[name]
+		if !ok {
+			return "", &ValidationError{
+				Field:  "template",
+				Reason: fmt.Sprintf("placeholder %q is missing from data", name),
+			}
+		}
+
+		b.WriteString(rest[:start])
+		b.WriteString(value)
+		rest = rest[start+2+end+2:]
+	}
+}
@@ -0,0 +1,58 @@
+package textbelt
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	data := map[string]string{"name": "Ana", "code": "1234", "empty": "", "braces": "{{name}}"}
+
+	for _, tc := range []struct {
+		template string
+		want     string
+		invalid  bool
+	}{
+		{template: "no placeholders", want: "no placeholders"},
+		{template: "", want: ""},
+		{template: "Hi {{name}}, your code is {{code}}", want: "Hi Ana, your code is 1234"},
+		{template: "{{ name }}{{name}}", want: "AnaAna"},
+		{template: "[{{empty}}]", want: "[]"},
+		{template: "values are not rendered: {{braces}}", want: "values are not rendered: {{name}}"},
+		{template: "single { and } braces", want: "single { and } braces"},
+		{template: "Hi {{missing}}", invalid: true},
+		{template: "Hi {{name", invalid: true},
+		{template: "Hi {{name}} {{", invalid: true},
+	} {
+		got, err := renderTemplate(tc.template, data)
+		if tc.invalid {
+			var verr *ValidationError
+			if !errors.As(err, &verr) || verr.Field != "template" {
+				t.Errorf("renderTemplate(%q) returned %q, %v, want a template ValidationError", tc.template, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("renderTemplate(%q) = %q, %v, want %q", tc.template, got, err, tc.want)
+		}
+	}
+}
+
+func TestSendTemplate(t *testing.T) {
+	srv := newFakeServer(t)
+	texter := New(WithURL(srv.URL))
+	ctx := context.Background()
+
+	if _, err := texter.SendTemplate(ctx, "+15555555555", "Hi {{name}}", map[string]string{"name": "Ana"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := texter.SendTemplate(ctx, "+15555555555", "Hi {{surname}}", map[string]string{"name": "Ana"}); err == nil {
+		t.Error("SendTemplate with a missing placeholder succeeded")
+	}
+
+	requests := srv.Requests()
+	if len(requests) != 1 || requests[0].Values.Get("message") != "Hi Ana" {
+		t.Errorf("sent %+v, want only %q", requests, "Hi Ana")
+	}
+}
@@ -1,22 +1,44 @@
 package textbelt
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"os"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
 )
 
+// Version is the version of the library sent in the default User-Agent
+const Version = "0.1.0"
+
+// DefaultKey is the free key allowing one message per day, used when no other key is passed
+const DefaultKey = "textbelt"
+
 // MessageStatus type can be used to check message state
 type MessageStatus string
 
 const (
-	key    = "textbelt"
 	apiURL = "https://textbelt.com"
 
+	testSuffix     = "_test"
+	maxWebhookData = 100
+	maxErrorBody   = 4 << 10
+
+	defaultMaxResponseBytes = 1 << 20
+
 	StatusDelivered MessageStatus = "DELIVERED"
 	StatusSent      MessageStatus = "SENT"
 	StatusSending   MessageStatus = "SENDING"
@@ -24,125 +46,512 @@ const (
 	StatusUnknown   MessageStatus = "UNKNOWN"
 )
 
-// New creates the new Textbelt object executing passed options
+// TimeoutEnv names the environment variable holding the default timeout as a Go duration, e.g. "10s"
+const TimeoutEnv = "TEXTBELT_TIMEOUT"
+
+// New creates the new Textbelt object executing passed options. New never fails, an invalid
+// option is ignored and its error is returned by every request, use NewWithError to get it upfront.
+// An invalid TimeoutEnv is ignored as well, and a non-positive WithTimeout disables the timeout
+// as it always did. Both are logged as a warning if WithLogger was used.
 func New(options ...func(*Textbelt)) *Textbelt {
 	t := &Textbelt{
-		key:     key,
-		url:     apiURL,
-		timeout: 5 * time.Second,
+		key:         DefaultKey,
+		url:         apiURL,
+		timeout:     5 * time.Second,
+		testMode:    new(atomic.Bool),
+		userAgent:   "textbelt-go/" + Version,
+		idempotency: newIdempotencyStore(),
+		otpExpiry:   newOTPExpiry(),
+		closeOnce:   new(sync.Once),
+		closed:      new(atomic.Bool),
+
+		maxResponseBytes: defaultMaxResponseBytes,
+		costPerSegment:   defaultCostPerSegment,
+		now:              time.Now,
 	}
 
+	envErr := t.timeoutFromEnv()
+
 	for _, opt := range options {
 		opt(t)
 	}
 
+	if envErr != nil && t.logger != nil {
+		t.logger.Warn("textbelt: ignoring "+TimeoutEnv, slog.Any("error", envErr))
+	}
+	if t.ignoredErr != nil && t.logger != nil {
+		t.logger.Warn("textbelt: ignoring option", slog.Any("error", t.ignoredErr))
+	}
+
+	t.start()
 	return t
 }
 
-// Textbelt struct is the main struct using which you will interact with textbelt API
+// start fills in what the options left unset and starts the background work
+func (t *Textbelt) start() {
+	if t.client == nil {
+		t.client = &http.Client{
+			Timeout:   t.timeout,
+			Transport: t.transport(),
+		}
+	}
+
+	if t.usedOTPs == nil {
+		t.usedOTPs = newMemoryOTPStore(t.now)
+	}
+
+	if t.quotaRefresh > 0 && t.err == nil {
+		t.startQuotaRefresh()
+	}
+}
+
+// NewWithError is like New but returns the error of the first invalid option, such as
+// a malformed URL or a non-positive timeout. It accepts the same options as New, so migrating
+// only requires handling the returned error. The options keep their func(*Textbelt) signature
+// instead of returning an error, so existing options and option slices keep compiling; each
+// option records its error on the Textbelt instead.
+func NewWithError(options ...func(*Textbelt)) (*Textbelt, error) {
+	t := New(options...)
+	if t.err != nil {
+		return nil, t.err
+	}
+	if t.ignoredErr != nil {
+		return nil, t.ignoredErr
+	}
+	return t, nil
+}
+
+// Textbelt struct is the main struct using which you will interact with textbelt API.
+//
+// A Textbelt is safe for concurrent use by multiple goroutines. Its configuration is set by
+// the options in New and never changes afterwards, everything changing at runtime (test mode,
+// quota cache, dry-run recorder, idempotency keys, rate limiter) is synchronized internally.
 type Textbelt struct {
-	key     string
-	url     string
-	timeout time.Duration
+	err        error // first error of the options, returned by every request
+	ignoredErr error // first error of an option New ignores for compatibility, only returned by NewWithError
+
+	key          string
+	keys         []string
+	keyFunc      func() (string, error)
+	fetchedKey   *atomic.Pointer[string] // last key returned by keyFunc, for redaction
+	url          string
+	timeout      time.Duration
+	client       *http.Client
+	customClient bool // client was passed with WithHTTPClient
+	proxy        *url.URL
+
+	dialTimeout           time.Duration
+	tlsHandshakeTimeout   time.Duration
+	responseHeaderTimeout time.Duration
+	insecureSkipVerify    bool
+
+	retries          int
+	retryBackoff     Backoff
+	operationTimeout time.Duration
+	limiter          *rate.Limiter
+	breaker          *breaker
+
+	validatePhone   bool
+	failFast        bool
+	defaultRegion   string
+	validateContent bool
+	jsonRequests    bool
+	strictDecoding  bool
+	captureRaw      bool
+	fieldMap        map[string]string
+	testMode        *atomic.Bool
+
+	logger    *slog.Logger
+	dump      io.Writer
+	userAgent string
+	headers   http.Header
+	basicAuth *basicAuth
+
+	maxResponseBytes int64
+
+	quota     *quotaCache
+	watermark *quotaWatermark
+	dryRun    *recorder
+	tracer    trace.Tracer
+	metrics   MetricsHook
+
+	idempotency *idempotencyStore
+	usedOTPs    UsedOTPStore
+	otpExpiry   *otpExpiry
+
+	costPerSegment int
+	multipliers    map[string]int
+	senders        map[string]string
+
+	now func() time.Time
+
+	quotaRefresh time.Duration
+	stop         context.CancelFunc // stops the background work
+	done         chan struct{}      // closed once the background work stopped
+	closeOnce    *sync.Once
+	closed       *atomic.Bool
 }
 
 type response struct {
 	Success        bool   `json:"success"`
 	Status         string `json:"status"`
-	ID             string `json:"textId"`
+	ID             textID `json:"textId"`
 	Error          string `json:"error"`
-	QuotaRemaining int    `json:"quotaRemaining"`
+	QuotaRemaining *int   `json:"quotaRemaining"` // nil if the response does not report it
 	OTP            string `json:"otp"`
 	ValidOTP       bool   `json:"isValidOtp"`
+
+	raw map[string]any // whole response, only decoded with WithCaptureRawResponse
+}
+
+// quota returns the reported quota, zero if it was not reported
+func (r *response) quota() int {
+	if r.QuotaRemaining == nil {
+		return 0
+	}
+	return *r.QuotaRemaining
+}
+
+// textID is the ID of a message, textbelt sends it as a JSON string but numbers are accepted too
+type textID string
+
+func (id *textID) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*id = textID(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return fmt.Errorf("textId must be a string or number: %w", err)
+	}
+	*id = textID(n)
+	return nil
 }
 
 // Quota returns the number of remaining amount of messages that can be sent
 func (t *Textbelt) Quota() (int, error) {
-	c := &http.Client{
-		Timeout: t.timeout,
+	return t.QuotaContext(context.Background())
+}
+
+// QuotaContext is like Quota but uses ctx for the request
+func (t *Textbelt) QuotaContext(ctx context.Context) (int, error) {
+	if remaining, ok := t.quota.get(t.now()); ok {
+		return remaining, nil
 	}
+	return t.QuotaFreshContext(ctx)
+}
 
-	u := fmt.Sprintf("%s/quota/%s", t.url, t.key)
-	resp, err := c.Get(u)
+// QuotaFresh is like Quota but always asks textbelt, bypassing the cache set with WithQuotaCache
+func (t *Textbelt) QuotaFresh() (int, error) {
+	return t.QuotaFreshContext(context.Background())
+}
+
+// QuotaFreshContext is like QuotaFresh but uses ctx for the request
+func (t *Textbelt) QuotaFreshContext(ctx context.Context) (int, error) {
+	key, err := t.apiKey()
 	if err != nil {
 		return -1, err
 	}
-	defer resp.Body.Close()
 
-	var r response
-	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+	r, err := t.do(ctx, "Quota", http.MethodGet, quotaPath(key), nil)
+	if err != nil {
 		return -1, err
 	}
-	return r.QuotaRemaining, nil
+	if r.QuotaRemaining == nil {
+		return -1, ErrQuotaUnavailable
+	}
+	t.setQuota(r.QuotaRemaining)
+	return *r.QuotaRemaining, nil
 }
 
 // Status returns the message status for specific message ID
 func (t *Textbelt) Status(id string) (MessageStatus, error) {
-	c := &http.Client{
-		Timeout: t.timeout,
-	}
+	return t.StatusContext(context.Background(), id)
+}
 
-	u := fmt.Sprintf("%s/status/%s", t.url, id)
-	resp, err := c.Get(u)
+// StatusContext is like Status but uses ctx for the request
+func (t *Textbelt) StatusContext(ctx context.Context, id string) (MessageStatus, error) {
+	r, err := t.do(ctx, "Status", http.MethodGet, statusPath(id), nil)
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
+	return ParseMessageStatus(r.Status), nil
+}
 
-	var r response
-	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
-		return "", err
-	}
-	return MessageStatus(r.Status), nil
+// SendResult holds the outcome of the sent message
+type SendResult struct {
+	ID             string        // ID of the message which can be passed to Status
+	QuotaRemaining int           // Number of messages left after this one, zero if not reported
+	QuotaReported  bool          // Whether textbelt reported QuotaRemaining
+	Success        bool          // Whether textbelt accepted the message
+	Status         MessageStatus // Initial status of the message, StatusUnknown if textbelt did not report it
+	Segments       int           // Number of SMS segments the content was split into, see SegmentCount
+	KeyIndex       int           // Index of the key passed to WithKeys the message was sent with
+
+	Raw map[string]any // Whole decoded response, only set with WithCaptureRawResponse
+
+	Phone string // Phone number of the receiver, only set by SendBatch
+	Err   error  // Error of the send, only set by SendBatch
 }
 
 // Send will send the message and will return the ID of the message
 func (t *Textbelt) Send(phone, content string) (string, error) {
+	return t.SendContext(context.Background(), phone, content)
+}
+
+// SendContext is like Send but uses ctx for the request and applies the SendOptions to the message
+func (t *Textbelt) SendContext(ctx context.Context, phone, content string, opts ...SendOption) (string, error) {
+	res, err := t.SendWithResultContext(ctx, phone, content, opts...)
+	if err != nil {
+		return "", err
+	}
+	return res.ID, nil
+}
+
+// SendWithResult will send the message and will return the full result including remaining quota
+func (t *Textbelt) SendWithResult(phone, content string) (*SendResult, error) {
+	return t.SendWithResultContext(context.Background(), phone, content)
+}
+
+// SendWithResultContext is like SendWithResult but uses ctx for the request and applies the SendOptions to the message
+func (t *Textbelt) SendWithResultContext(ctx context.Context, phone, content string, opts ...SendOption) (*SendResult, error) {
+	msg := &Message{
+		Phone:   phone,
+		Content: content,
+	}
+	for _, opt := range opts {
+		opt(msg)
+	}
+	return t.SendMessageContext(ctx, msg)
+}
+
+// SendAt schedules the message to be delivered at when. If when has a location other
+// than UTC or Local attached, its IANA name is sent as the timezone.
+func (t *Textbelt) SendAt(ctx context.Context, phone, content string, when time.Time) (*SendResult, error) {
+	return t.SendWithResultContext(ctx, phone, content, WithSendAt(when))
+}
+
+// CancelScheduled would cancel the message scheduled with SendAt before it is delivered. textbelt
+// has no endpoint for cancelling scheduled messages, so it always returns ErrNotSupported without
+// making a request. It is kept so callers can depend on it once textbelt adds one.
+func (t *Textbelt) CancelScheduled(ctx context.Context, id string) error {
+	return ErrNotSupported
+}
+
+// SendMMS sends the message together with the image at mediaURL, which must be an absolute
+// http or https URL reachable by textbelt. Textbelt publishes no list of the countries and
+// carriers MMS is delivered to and the library has not verified any, so the receiver may get
+// the text only or textbelt may reject the message.
+func (t *Textbelt) SendMMS(ctx context.Context, phone, content, mediaURL string) (*SendResult, error) {
+	if err := checkMediaURL(mediaURL); err != nil {
+		return nil, err
+	}
+	return t.SendWithResultContext(ctx, phone, content, WithMediaURL(mediaURL))
+}
+
+// Message enables you to customize the message being sent
+type Message struct {
+	Phone   string // Phone number of the receiver
+	Content string // Content of the message
+	Sender  string // Optional name of the sender shown to the receiver
+
+	ReplyWebhookURL string // Optional URL textbelt will POST replies from the receiver to
+	WebhookData     string // Optional data passed back with the reply webhook, at most 100 characters
+
+	StatusCallbackURL string // Optional URL textbelt will POST delivery status updates to, see ParseStatusWebhook
+
+	SendAt time.Time // Optional time to deliver the message at, must not be in the past
+
+	MediaURL string // Optional absolute http or https URL of an image to send as MMS, see SendMMS
+
+	// Extra holds additional form fields for parameters not supported by the library yet.
+	// The phone, message and key fields are reserved, fields set through Message take precedence.
+	Extra map[string]string
+}
+
+// SendMessage will send the message described by msg and will return the full result
+func (t *Textbelt) SendMessage(msg *Message) (*SendResult, error) {
+	return t.SendMessageContext(context.Background(), msg)
+}
+
+// SendMessageContext is like SendMessage but uses ctx for the request
+func (t *Textbelt) SendMessageContext(ctx context.Context, msg *Message) (*SendResult, error) {
+	phone, err := t.validateMessage(msg)
+	if err != nil {
+		return nil, err
+	}
+
 	values := url.Values{
 		"phone":   {phone},
-		"message": {content},
-		"key":     {t.key},
+		"message": {msg.Content},
 	}
 
-	c := &http.Client{
-		Timeout: t.timeout,
+	sender := msg.Sender
+	if sender == "" && t.senders != nil {
+		if _, region, ok := phoneRegion(phone); ok {
+			sender = t.senders[region]
+		}
 	}
 
-	u := t.url + "/text"
+	if sender != "" {
+		values.Add("sender", sender)
+	}
 
-	resp, err := c.PostForm(u, values)
+	if msg.ReplyWebhookURL != "" {
+		values.Add("replyWebhookUrl", msg.ReplyWebhookURL)
+	}
+
+	if msg.WebhookData != "" {
+		values.Add("webhookData", msg.WebhookData)
+	}
+
+	if msg.StatusCallbackURL != "" {
+		values.Add("statusCallbackUrl", msg.StatusCallbackURL)
+	}
+
+	if !msg.SendAt.IsZero() {
+		values.Add("sendAt", strconv.FormatInt(msg.SendAt.Unix(), 10))
+		if loc := msg.SendAt.Location(); loc != time.UTC && loc != time.Local {
+			values.Add("sendAtTimezone", loc.String())
+		}
+	}
+
+	if msg.MediaURL != "" {
+		values.Add("mediaUrl", msg.MediaURL)
+	}
+
+	for k, v := range msg.Extra {
+		if !values.Has(k) {
+			values.Set(k, v)
+		}
+	}
+
+	r, idx, err := t.postWithKeys(ctx, "Send", "/text", values)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	var r response
-	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
-		return "", err
+	if idx == 0 {
+		t.setQuota(r.QuotaRemaining)
 	}
 
-	if !r.Success {
-		return "", errors.New(r.Error)
+	return &SendResult{
+		ID:             string(r.ID),
+		QuotaRemaining: r.quota(),
+		QuotaReported:  r.QuotaRemaining != nil,
+		Success:        r.Success,
+		Status:         ParseMessageStatus(r.Status),
+		Segments:       SegmentCount(msg.Content),
+		KeyIndex:       idx,
+		Raw:            r.raw,
+	}, nil
+}
+
+// DoText posts values as they are to the /text endpoint and decodes the standard response, which
+// enables you to use parameters not supported by Message yet. The phone and message fields are
+// required. The key field is filled in like for Send, including WithKeys fallover, unless it is set.
+// No other validation is done and the quota cache is only updated when the key is filled in.
+func (t *Textbelt) DoText(ctx context.Context, values url.Values) (*SendResult, error) {
+	for _, field := range []string{"phone", "message"} {
+		if values.Get(field) == "" {
+			return nil, &ValidationError{
+				Field:  field,
+				Reason: "is required",
+			}
+		}
 	}
+	values = cloneValues(values)
 
-	return r.ID, nil
+	var (
+		r   *response
+		idx int
+		err error
+	)
+	if values.Has("key") {
+		r, err = t.doChecked(ctx, "Send", http.MethodPost, "/text", values, checkSend)
+	} else {
+		r, idx, err = t.postWithKeys(ctx, "Send", "/text", values)
+		if err == nil && idx == 0 {
+			t.setQuota(r.QuotaRemaining)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &SendResult{
+		ID:             string(r.ID),
+		QuotaRemaining: r.quota(),
+		QuotaReported:  r.QuotaRemaining != nil,
+		Success:        r.Success,
+		Status:         ParseMessageStatus(r.Status),
+		Segments:       SegmentCount(values.Get("message")),
+		KeyIndex:       idx,
+		Raw:            r.raw,
+	}, nil
 }
 
 // CustomOTP enables you to customize your OTP messages
 type CustomOTP struct {
 	Phone    string // Phone number of the receiver
 	UserID   string // UserID - arbitrary ID for the generated OTP
-	Message  string // Custom message, $OTP inside will hold the actual content
-	Lifetime int    // How long the OTP should last
-	Length   int    // Number of digits inside the OTP
+	Message  string // Custom message, must contain $OTP which will hold the actual content
+	Lifetime int    // How long in seconds the OTP should last, 1 to 86400, 0 uses the server default of 180
+	Length   int    // Number of digits inside the OTP, 4 to 10, 0 or negative uses the server default of 6
+}
+
+// OTPResult holds the outcome of the OTP generation
+type OTPResult struct {
+	OTP            string // Generated OTP
+	ID             string // ID of the message carrying the OTP which can be passed to Status
+	QuotaRemaining int    // Number of messages left after this one, zero if not reported
+	QuotaReported  bool   // Whether textbelt reported QuotaRemaining
+	KeyIndex       int    // Index of the key passed to WithKeys the OTP was sent with
+
+	Raw map[string]any // Whole decoded response, only set with WithCaptureRawResponse
+
+	UserID string // UserID of the OTP, only set by GenerateOTPBatch
+	Err    error  // Error of the generation, only set by GenerateOTPBatch
 }
 
 // GenerateCustomOTP enables you to customize your OTP message by providing CustomOTP pointer
 func (t *Textbelt) GenerateCustomOTP(otp *CustomOTP) (string, error) {
+	return t.GenerateCustomOTPContext(context.Background(), otp)
+}
+
+// GenerateCustomOTPContext is like GenerateCustomOTP but uses ctx for the request
+func (t *Textbelt) GenerateCustomOTPContext(ctx context.Context, otp *CustomOTP) (string, error) {
+	res, err := t.GenerateCustomOTPWithResultContext(ctx, otp)
+	if err != nil {
+		return "", err
+	}
+	return res.OTP, nil
+}
+
+// GenerateCustomOTPWithResult is like GenerateCustomOTP but returns the full result including the message ID
+func (t *Textbelt) GenerateCustomOTPWithResult(otp *CustomOTP) (*OTPResult, error) {
+	return t.GenerateCustomOTPWithResultContext(context.Background(), otp)
+}
+
+// GenerateCustomOTPWithResultContext is like GenerateCustomOTPWithResult but uses ctx for the request
+func (t *Textbelt) GenerateCustomOTPWithResultContext(ctx context.Context, otp *CustomOTP) (*OTPResult, error) {
+	phone, err := t.validateOTP(otp)
+	if err != nil {
+		return nil, err
+	}
+
 	values := url.Values{
-		"phone":  {otp.Phone},
+		"phone":  {phone},
 		"userid": {otp.UserID},
-		"key":    {t.key},
 	}
 
 	if otp.Message != "" {
@@ -157,98 +566,618 @@ func (t *Textbelt) GenerateCustomOTP(otp *CustomOTP) (string, error) {
 		values.Add("length", strconv.Itoa(otp.Length))
 	}
 
-	return t.sendOTP(values)
+	// taken before sending so the tracked lifetime never outlasts the real one
+	start := t.now()
+	res, err := t.sendOTP(ctx, values)
+	if err != nil {
+		return nil, err
+	}
+	t.otpExpiry.generated(otp.UserID, otp.Lifetime, start)
+	return res, nil
 }
 
 // GenerateOTP will generate the OTP and send the message to the user and will return the generated OTP
 func (t *Textbelt) GenerateOTP(phone, userid string) (string, error) {
-	values := url.Values{
-		"phone":  {phone},
-		"userid": {userid},
-		"key":    {t.key},
+	return t.GenerateOTPContext(context.Background(), phone, userid)
+}
+
+// GenerateOTPContext is like GenerateOTP but uses ctx for the request
+func (t *Textbelt) GenerateOTPContext(ctx context.Context, phone, userid string) (string, error) {
+	res, err := t.GenerateOTPWithResultContext(ctx, phone, userid)
+	if err != nil {
+		return "", err
 	}
+	return res.OTP, nil
+}
 
-	return t.sendOTP(values)
+// GenerateOTPWithResult is like GenerateOTP but returns the full result including the message ID
+func (t *Textbelt) GenerateOTPWithResult(phone, userid string) (*OTPResult, error) {
+	return t.GenerateOTPWithResultContext(context.Background(), phone, userid)
 }
 
-// VerifyOTP checks whether the specified otp and userid are valid
+// GenerateOTPWithResultContext is like GenerateOTPWithResult but uses ctx for the request
+func (t *Textbelt) GenerateOTPWithResultContext(ctx context.Context, phone, userid string) (*OTPResult, error) {
+	return t.GenerateCustomOTPWithResultContext(ctx, &CustomOTP{
+		Phone:  phone,
+		UserID: userid,
+	})
+}
+
+// VerifyOTP checks whether the specified otp and userid are valid. A wrong otp returns false with
+// a nil error. Network and decoding failures return false with that error, and failures reported
+// by textbelt, e.g. an unknown userid, return false with an APIError.
 func (t *Textbelt) VerifyOTP(otp, userid string) (bool, error) {
-	u := fmt.Sprintf("%s/otp/verify", t.url)
-	req, err := http.NewRequest("GET", u, nil)
+	return t.VerifyOTPContext(context.Background(), otp, userid)
+}
+
+// VerifyOTPContext is like VerifyOTP but uses ctx for the request
+func (t *Textbelt) VerifyOTPContext(ctx context.Context, otp, userid string) (bool, error) {
+	res, err := t.VerifyOTPWithResultContext(ctx, otp, userid)
 	if err != nil {
 		return false, err
 	}
+	return res.Valid, nil
+}
 
-	q := req.URL.Query()
-	q.Add("otp", otp)
-	q.Add("userid", userid)
-	q.Add("key", t.key)
+// VerifyOTPResult holds the outcome of the OTP verification
+type VerifyOTPResult struct {
+	Valid   bool   // Whether the OTP is valid for the userid
+	Message string // Diagnostic message returned by textbelt, if any
 
-	req.URL.RawQuery = q.Encode()
+	// RemainingLifetime is how long the last OTP generated for the userid by this Textbelt is valid,
+	// zero if it expired. textbelt does not report expiry, so the time is tracked from the generation
+	// and is zero for OTPs generated elsewhere, e.g. by another process.
+	RemainingLifetime time.Duration
 
-	c := &http.Client{
-		Timeout: t.timeout,
-	}
+	// StoredOTP is the OTP textbelt had stored for the userid, only set when the verification
+	// succeeded and textbelt included it in the response, which it currently does not
+	StoredOTP string
+}
+
+// VerifyOTPWithResult is like VerifyOTP but returns the full result. A wrong OTP is reported
+// as Valid set to false with no error, while failures such as an unknown userid are returned as errors.
+func (t *Textbelt) VerifyOTPWithResult(otp, userid string) (*VerifyOTPResult, error) {
+	return t.VerifyOTPWithResultContext(context.Background(), otp, userid)
+}
 
-	resp, err := c.Do(req)
+// VerifyOTPWithResultContext is like VerifyOTPWithResult but uses ctx for the request
+func (t *Textbelt) VerifyOTPWithResultContext(ctx context.Context, otp, userid string) (*VerifyOTPResult, error) {
+	key, err := t.apiKey()
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	var r response
-	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
-		return false, err
+	values := url.Values{
+		"otp":    {otp},
+		"userid": {userid},
+		"key":    {key},
 	}
 
-	if !r.Success {
-		return false, errors.New(r.Error)
+	r, err := t.doChecked(ctx, "VerifyOTP", http.MethodGet, "/otp/verify", values, checkSuccess)
+	if err != nil {
+		return nil, err
 	}
 
-	return r.ValidOTP, err
+	res := &VerifyOTPResult{
+		Valid:             r.ValidOTP,
+		Message:           r.Error,
+		RemainingLifetime: t.otpExpiry.remaining(userid, t.now()),
+	}
+	if r.ValidOTP {
+		res.StoredOTP = r.OTP
+	}
+	return res, nil
 }
 
-// WithURL enables you to pass custom textbelt API endpoint
-func WithURL(url string) func(*Textbelt) {
+// WithURL enables you to pass custom textbelt API endpoint. The URL must be absolute with
+// an http or https scheme, relative URLs are rejected, and a trailing slash is stripped.
+// An invalid URL is returned by NewWithError, with New it is returned by every request.
+func WithURL(rawURL string) func(*Textbelt) {
 	return func(t *Textbelt) {
-		t.url = url
+		u, err := parseBaseURL(rawURL)
+		if err != nil {
+			t.setErr(err)
+			return
+		}
+		t.url = u
 	}
 }
 
-// WithURL enables you to pass your own API key, otherwise free "textbelt" key will be used
+// WithKey enables you to pass your own API key, otherwise DefaultKey will be used
 func WithKey(key string) func(*Textbelt) {
 	return func(t *Textbelt) {
 		t.key = key
+		t.keys = nil
+		t.keyFunc = nil
 	}
 }
 
-// WithTimeout enables you to set timeout for requests, otherwise 5 seconds will be used
+// WithDefaultFreeKey enables you to state explicitly that the free DefaultKey is used,
+// it overrides keys passed by earlier options
+func WithDefaultFreeKey() func(*Textbelt) {
+	return WithKey(DefaultKey)
+}
+
+// WithTimeout enables you to set timeout for requests, otherwise the duration in TimeoutEnv or
+// 5 seconds will be used. The timeout applies to every request alongside the deadline of the
+// context passed to the Context methods, whichever is shorter wins. Use a context deadline to
+// give a single call less time, WithTimeout to give every call more. A non-positive timeout
+// means no timeout as in http.Client, it is not an error for New but for NewWithError.
 func WithTimeout(timeout time.Duration) func(*Textbelt) {
 	return func(t *Textbelt) {
+		if timeout <= 0 && t.ignoredErr == nil {
+			t.ignoredErr = &ValidationError{
+				Field:  "timeout",
+				Reason: "must be positive",
+			}
+		}
 		t.timeout = timeout
 	}
 }
 
-func (t *Textbelt) sendOTP(values url.Values) (string, error) {
-	c := &http.Client{
-		Timeout: t.timeout,
+// WithTestMode enables you to send messages that are validated by textbelt but never delivered
+// and do not use quota, "_test" is appended to the configured key
+func WithTestMode() func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.testMode.Store(true)
+	}
+}
+
+// SetTestMode turns the test mode described in WithTestMode on or off, it is safe to call
+// while requests are in flight
+func (t *Textbelt) SetTestMode(enabled bool) {
+	t.testMode.Store(enabled)
+}
+
+// WithUserAgent enables you to set the User-Agent sent with every request, otherwise "textbelt-go/<Version>" will be used
+func WithUserAgent(ua string) func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.userAgent = ua
 	}
+}
 
-	u := fmt.Sprintf("%s/otp/generate", t.url)
-	resp, err := c.PostForm(u, values)
+// WithDefaultHeaders enables you to send additional headers with every request, e.g. a proxy
+// authorization header. They override the User-Agent, but Content-Type is always set by the library.
+func WithDefaultHeaders(h http.Header) func(*Textbelt) {
+	return func(t *Textbelt) {
+		if t.headers == nil {
+			t.headers = make(http.Header, len(h))
+		}
+		for k, vs := range h {
+			t.headers[http.CanonicalHeaderKey(k)] = append([]string(nil), vs...)
+		}
+	}
+}
+
+// WithClock enables you to replace time.Now for everything depending on the current time, such as
+// the quota cache, sendAt validation, idempotency keys and the circuit breaker cooldown, so tests can
+// control time. Timeouts, retry delays and polling intervals still use real time.
+func WithClock(now func() time.Time) func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.now = now
+	}
+}
+
+// basicAuth holds the credentials set with WithBasicAuth
+type basicAuth struct {
+	username string
+	password string
+}
+
+// WithBasicAuth enables you to send HTTP basic auth credentials with every request, which is meant
+// for self-hosted textbelt instances behind an authenticating proxy, see WithURL. The key is sent
+// as usual. The credentials override an Authorization header set with WithDefaultHeaders.
+func WithBasicAuth(username, password string) func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.basicAuth = &basicAuth{
+			username: username,
+			password: password,
+		}
+	}
+}
+
+// WithJSONRequests enables you to send the parameters of POST requests as a JSON body instead of a form,
+// the keys of the JSON object are the same as the form fields
+func WithJSONRequests() func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.jsonRequests = true
+	}
+}
+
+// WithRateLimit enables you to send at most rps requests per second with bursts of up to burst requests.
+// Every request, retries included, waits for its turn or until its context is done. The limit is per
+// Textbelt, so sharing one Textbelt shares the budget.
+func WithRateLimit(rps float64, burst int) func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.limiter = rate.NewLimiter(rate.Limit(rps), burst)
+	}
+}
+
+// WithMaxResponseBytes enables you to limit how much of the response body is read, otherwise 1MB will be used.
+// Larger responses fail with ErrResponseTooLarge.
+func WithMaxResponseBytes(n int64) func(*Textbelt) {
+	return func(t *Textbelt) {
+		if n <= 0 {
+			t.setErr(&ValidationError{
+				Field:  "maxResponseBytes",
+				Reason: "must be positive",
+			})
+			return
+		}
+		t.maxResponseBytes = n
+	}
+}
+
+// WithStrictDecoding enables you to reject responses containing fields the library does not know
+// about with a DecodeError, which helps to notice changes of the textbelt API in tests or staging.
+// Unknown fields are ignored by default.
+func WithStrictDecoding() func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.strictDecoding = true
+	}
+}
+
+// WithCaptureRawResponse enables you to get the whole decoded response in the Raw field of
+// SendResult and OTPResult, e.g. to read fields the library does not support yet
+func WithCaptureRawResponse() func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.captureRaw = true
+	}
+}
+
+// WithHTTPClient enables you to pass your own http.Client which will be reused for all requests.
+// It takes precedence over WithTimeout, WithProxy, WithTransportTimeouts and WithInsecureSkipVerify,
+// the passed client is used as is.
+func WithHTTPClient(c *http.Client) func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.client = c
+		t.customClient = true
+	}
+}
+
+// Close stops the background work started by New and closes idle connections of the http.Client.
+// Every request made afterwards fails with ErrClosed. It is safe to call multiple times.
+func (t *Textbelt) Close() error {
+	t.closeOnce.Do(func() {
+		t.closed.Store(true)
+		if t.stop != nil {
+			t.stop()
+			<-t.done
+		}
+		t.client.CloseIdleConnections()
+	})
+	return nil
+}
+
+// Timeout returns the timeout of the http.Client used for requests
+func (t *Textbelt) Timeout() time.Duration {
+	return t.client.Timeout
+}
+
+// BaseURL returns the textbelt API endpoint requests are sent to
+func (t *Textbelt) BaseURL() string {
+	return t.url
+}
+
+// HasCustomKey reports whether a key other than DefaultKey is configured
+func (t *Textbelt) HasCustomKey() bool {
+	return t.keyFunc != nil || t.key != DefaultKey
+}
+
+// setErr records the error of an option unless an earlier option already failed
+func (t *Textbelt) setErr(err error) {
+	if t.err == nil {
+		t.err = err
+	}
+}
+
+// parseBaseURL validates rawURL and strips its trailing slash
+func parseBaseURL(rawURL string) (string, error) {
+	if rawURL == "" {
+		return "", &ValidationError{
+			Field:  "url",
+			Reason: "is empty",
+		}
+	}
+
+	u, err := url.Parse(rawURL)
 	if err != nil {
-		return "", err
+		return "", &ValidationError{
+			Field:  "url",
+			Reason: err.Error(),
+		}
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", &ValidationError{
+			Field:  "url",
+			Reason: "scheme must be http or https",
+		}
+	}
+
+	if u.Host == "" {
+		return "", &ValidationError{
+			Field:  "url",
+			Reason: "host is missing",
+		}
+	}
+
+	return strings.TrimRight(u.String(), "/"), nil
+}
+
+// quotaPath returns the path of the quota of key, escaping it as a single path segment
+func quotaPath(key string) string {
+	return "/quota/" + url.PathEscape(key)
+}
+
+// statusPath returns the path of the status of the message id, escaping it as a single path segment
+func statusPath(id string) string {
+	return "/status/" + url.PathEscape(id)
+}
+
+// apiKey returns the key to send with the request, fetching it if WithKeyFunc was used
+func (t *Textbelt) apiKey() (string, error) {
+	key := t.key
+	if t.keyFunc != nil {
+		k, err := t.keyFunc()
+		if err == nil && k == "" {
+			err = errors.New("key is empty")
+		}
+		if err != nil {
+			return "", fmt.Errorf("textbelt: fetching key: %w", err)
+		}
+		t.fetchedKey.Store(&k)
+		key = k
+	}
+
+	if t.testMode.Load() {
+		return key + testSuffix, nil
+	}
+	return key, nil
+}
+
+func (t *Textbelt) sendOTP(ctx context.Context, values url.Values) (*OTPResult, error) {
+	r, idx, err := t.postWithKeys(ctx, "GenerateOTP", "/otp/generate", values)
+	if err != nil {
+		return nil, err
+	}
+
+	if idx == 0 {
+		t.setQuota(r.QuotaRemaining)
+	}
+
+	return &OTPResult{
+		OTP:            r.OTP,
+		ID:             string(r.ID),
+		QuotaRemaining: r.quota(),
+		QuotaReported:  r.QuotaRemaining != nil,
+		KeyIndex:       idx,
+		Raw:            r.raw,
+	}, nil
+}
+
+// do sends the request to the path relative to the API url and decodes the response,
+// retrying it if WithRetry was used. The op names the method for tracing and metrics.
+// For GET requests values are sent as the query string, for POST requests as the form body.
+func (t *Textbelt) do(ctx context.Context, op, method, path string, values url.Values) (*response, error) {
+	return t.doChecked(ctx, op, method, path, values, nil)
+}
+
+// doChecked is like do but turns the decoded response into an error with check, if not nil, such as
+// checkSuccess for endpoints reporting success. Metrics and spans see the error returned by check.
+func (t *Textbelt) doChecked(ctx context.Context, op, method, path string, values url.Values, check func(*response) error) (r *response, err error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+
+	if t.closed.Load() {
+		return nil, ErrClosed
+	}
+
+	if t.dryRun != nil {
+		if r, ok := t.dryRun.handle(method, path, values); ok {
+			return r, nil
+		}
+	}
+
+	if t.operationTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.operationTimeout)
+		defer cancel()
+	}
+
+	if t.metrics != nil {
+		start := time.Now()
+		defer func() {
+			t.observe(op, start, err)
+		}()
+	}
+
+	if t.tracer != nil {
+		var span trace.Span
+		ctx, span = t.startSpan(ctx, op)
+		defer func() {
+			endSpan(span, r, err)
+		}()
+	}
+
+	for attempt := 1; ; attempt++ {
+		if t.limiter != nil {
+			if err := t.limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		var trial bool
+		if t.breaker != nil {
+			var err error
+			if trial, err = t.breaker.allow(t.now()); err != nil {
+				return nil, err
+			}
+		}
+
+		r, err := t.attempt(ctx, method, path, values)
+		if t.breaker != nil {
+			t.breaker.record(trial, err, t.now())
+		}
+		if err == nil && check != nil {
+			return r, check(r)
+		}
+		if err == nil || attempt >= t.retries || !retryable(err) {
+			return r, err
+		}
+
+		if err := sleep(ctx, t.backoff(attempt, err)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attempt sends the request once
+func (t *Textbelt) attempt(ctx context.Context, method, path string, values url.Values) (*response, error) {
+	var body io.Reader
+	contentType := "application/x-www-form-urlencoded"
+	if method == http.MethodPost {
+		if t.jsonRequests {
+			b, err := json.Marshal(jsonValues(values))
+			if err != nil {
+				return nil, err
+			}
+			body = bytes.NewReader(b)
+			contentType = "application/json"
+		} else {
+			body = strings.NewReader(values.Encode())
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, t.url+path, body)
+	if err != nil {
+		return nil, t.redactError(err)
+	}
+
+	req.Header.Set("User-Agent", t.userAgent)
+	for k, vs := range t.headers {
+		req.Header[k] = append([]string(nil), vs...)
+	}
+	if t.basicAuth != nil {
+		req.SetBasicAuth(t.basicAuth.username, t.basicAuth.password)
+	}
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", contentType)
+	} else if len(values) > 0 {
+		req.URL.RawQuery = values.Encode()
+	}
+
+	t.logRequest(ctx, req, values)
+	if t.dump != nil {
+		t.dumpRequest(req, values)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		err = t.redactError(err)
+		t.logResponse(ctx, 0, nil, err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if t.dump != nil {
+		t.dumpResponse(resp, values)
+	}
+
+	r, err := t.decode(resp)
+	t.logResponse(ctx, resp.StatusCode, r, err)
+	return r, err
+}
+
+// jsonValues flattens values into the object sent as the JSON body, keeping the first value of every key
+func jsonValues(values url.Values) map[string]string {
+	m := make(map[string]string, len(values))
+	for k := range values {
+		m[k] = values.Get(k)
+	}
+	return m
+}
+
+// decode checks the status code of the response and decodes its body
+func (t *Textbelt) decode(resp *http.Response) (*response, error) {
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		herr := &HTTPError{
+			StatusCode: resp.StatusCode,
+			Body:       string(b),
+			Err:        bodyError(b),
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			herr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+		}
+		return nil, herr
+	}
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, t.maxResponseBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > t.maxResponseBytes {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, t.maxResponseBytes)
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return nil, fmt.Errorf("%w with status %d %s", ErrEmptyResponse, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
 	var r response
-	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
-		return "", err
+	if err := t.unmarshal(t.mapFields(b), &r); err != nil {
+		if len(b) > maxErrorBody {
+			b = b[:maxErrorBody]
+		}
+		return nil, &DecodeError{
+			Err:        err,
+			Body:       b,
+			StatusCode: resp.StatusCode,
+		}
 	}
 
-	if !r.Success {
-		return "", errors.New(r.Error)
+	if t.captureRaw {
+		// b already decoded into r, so it is valid JSON
+		_ = json.Unmarshal(b, &r.raw)
+	}
+	return &r, nil
+}
+
+// unmarshal decodes b into v, rejecting unknown fields if WithStrictDecoding was used
+func (t *Textbelt) unmarshal(b []byte, v any) error {
+	if !t.strictDecoding {
+		return json.Unmarshal(b, v)
 	}
 
-	return r.OTP, nil
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("invalid data after top-level value")
+	}
+	return nil
+}
+
+// timeoutFromEnv sets the timeout from TimeoutEnv, invalid values are ignored and returned
+func (t *Textbelt) timeoutFromEnv() error {
+	v := os.Getenv(TimeoutEnv)
+	if v == "" {
+		return nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return err
+	}
+	if d <= 0 {
+		return fmt.Errorf("duration %s is not positive", v)
+	}
+	t.timeout = d
+	return nil
 }
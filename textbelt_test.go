@@ -0,0 +1,250 @@
+package textbelt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lateralusd/textbelt/textbelttest"
+)
+
+// newFakeServer starts a fake textbelt server closed at the end of the test
+func newFakeServer(t *testing.T) *textbelttest.Server {
+	t.Helper()
+
+	srv := textbelttest.NewFakeServer()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// TestConcurrentUse is meant to be run with -race
+func TestConcurrentUse(t *testing.T) {
+	srv := newFakeServer(t)
+	texter := New(WithURL(srv.URL), WithKey("key"), WithQuotaCache(time.Millisecond))
+	defer texter.Close()
+
+	var wg sync.WaitGroup
+	for i := range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			texter.SetTestMode(i%2 == 0)
+			if _, err := texter.Send("+15555555555", "test message"); err != nil {
+				t.Errorf("Send: %v", err)
+			}
+			if _, err := texter.Status("1"); err != nil {
+				t.Errorf("Status: %v", err)
+			}
+			if _, err := texter.Quota(); err != nil {
+				t.Errorf("Quota: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPathEscaping(t *testing.T) {
+	const id, key = "a/b#c?d", "k/e#y?"
+
+	// a wrongly escaped id or key would not match the single path segment of the fake server
+	srv := newFakeServer(t)
+	srv.SetStatus(id, "SENDING")
+	srv.SetQuota(key, 7)
+
+	texter := New(WithURL(srv.URL), WithKey(key))
+	if status, err := texter.Status(id); err != nil || status != StatusSending {
+		t.Errorf("Status = %v, %v, want SENDING", status, err)
+	}
+	if remaining, err := texter.Quota(); err != nil || remaining != 7 {
+		t.Errorf("Quota = %d, %v, want 7", remaining, err)
+	}
+
+	want := []string{"/status/" + id, "/quota/" + key}
+	requests := srv.Requests()
+	if len(requests) != len(want) {
+		t.Fatalf("got %d requests, want %d", len(requests), len(want))
+	}
+	for i := range want {
+		if requests[i].Path != want[i] {
+			t.Errorf("requested %q, want %q", requests[i].Path, want[i])
+		}
+	}
+}
+
+func TestSendMMS(t *testing.T) {
+	srv := newFakeServer(t)
+	texter := New(WithURL(srv.URL))
+	ctx := context.Background()
+
+	if _, err := texter.SendMMS(ctx, "+15555555555", "look at this", "https://example.com/cat.png"); err != nil {
+		t.Fatal(err)
+	}
+	for _, mediaURL := range []string{"", "cat.png", "ftp://example.com/cat.png", "https://"} {
+		_, err := texter.SendMMS(ctx, "+15555555555", "look at this", mediaURL)
+		var verr *ValidationError
+		if !errors.As(err, &verr) || verr.Field != "mediaUrl" {
+			t.Errorf("SendMMS with media URL %q returned %v, want a mediaUrl ValidationError", mediaURL, err)
+		}
+	}
+
+	requests := srv.Requests()
+	if len(requests) != 1 || requests[0].Values.Get("mediaUrl") != "https://example.com/cat.png" {
+		t.Errorf("sent %+v, want only the valid media URL", requests)
+	}
+}
+
+func TestVerifyOTP(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		url       string // used instead of the test server if set
+		wantValid bool
+		wantAPI   bool
+		wantErr   bool
+	}{
+		{name: "valid", body: `{"success":true,"isValidOtp":true}`, wantValid: true},
+		{name: "wrong otp", body: `{"success":true,"isValidOtp":false}`},
+		{name: "transport failure", url: "http://127.0.0.1:1", wantErr: true},
+		{name: "decode failure", body: `<html>bad gateway</html>`, wantErr: true},
+		{name: "api error", body: `{"success":false,"error":"Invalid key"}`, wantAPI: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := tt.url
+			if u == "" {
+				srv := newFakeServer(t)
+				srv.SetResponse("/otp/verify", http.StatusOK, tt.body)
+				u = srv.URL
+			}
+
+			valid, err := New(WithURL(u)).VerifyOTP("123456", "u1")
+			if valid != tt.wantValid {
+				t.Errorf("valid = %v, want %v", valid, tt.wantValid)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+
+			var aerr *APIError
+			if errors.As(err, &aerr) != tt.wantAPI {
+				t.Errorf("err = %v, want *APIError %v", err, tt.wantAPI)
+			}
+		})
+	}
+}
+
+func TestTextID(t *testing.T) {
+	tests := []struct {
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{body: `{"success":true,"textId":"12345"}`, want: "12345"},
+		{body: `{"success":true,"textId":12345}`, want: "12345"},
+		{body: `{"success":true,"textId":123456789012345678901}`, want: "123456789012345678901"},
+		{body: `{"success":true,"textId":null}`, want: ""},
+		{body: `{"success":true,"textId":true}`, wantErr: true},
+		{body: `{"success":true,"textId":{"id":1}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		srv := newFakeServer(t)
+		srv.SetResponse("/text", http.StatusOK, tt.body)
+		id, err := New(WithURL(srv.URL)).Send("+15555555555", "test message")
+		if tt.wantErr {
+			var derr *DecodeError
+			if !errors.As(err, &derr) {
+				t.Errorf("%s: err = %v, want a DecodeError", tt.body, err)
+			}
+			continue
+		}
+		if err != nil || id != tt.want {
+			t.Errorf("%s: Send = %q, %v, want %q", tt.body, id, err, tt.want)
+		}
+	}
+}
+
+func TestEmptyResponse(t *testing.T) {
+	calls := map[string]func(*Textbelt) error{
+		"Send": func(t *Textbelt) error {
+			_, err := t.Send("+15555555555", "test message")
+			return err
+		},
+		"Quota": func(t *Textbelt) error {
+			_, err := t.Quota()
+			return err
+		},
+		"Status": func(t *Textbelt) error {
+			_, err := t.Status("1")
+			return err
+		},
+		"GenerateOTP": func(t *Textbelt) error {
+			_, err := t.GenerateOTP("+15555555555", "u1")
+			return err
+		},
+		"VerifyOTP": func(t *Textbelt) error {
+			_, err := t.VerifyOTP("123456", "u1")
+			return err
+		},
+	}
+
+	for _, body := range []string{"", " \n\t"} {
+		// the fake server can not answer with an empty body
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		}))
+		defer srv.Close()
+		texter := New(WithURL(srv.URL))
+		for name, call := range calls {
+			if err := call(texter); !errors.Is(err, ErrEmptyResponse) {
+				t.Errorf("%s with body %q: err = %v, want ErrEmptyResponse", name, body, err)
+			}
+		}
+	}
+}
+
+func TestQuotaReported(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		quota int
+	}{
+		{name: "present", body: `{"success":true,"textId":"1","quotaRemaining":7}`, quota: 7},
+		{name: "zero", body: `{"success":true,"textId":"1","quotaRemaining":0}`, quota: 0},
+		{name: "absent", body: `{"success":true,"textId":"1"}`, quota: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFakeServer(t)
+			srv.SetResponse("/text", http.StatusOK, tt.body)
+			srv.SetResponse("/quota", http.StatusOK, tt.body)
+			texter := New(WithURL(srv.URL))
+
+			res, err := texter.SendMessage(&Message{Phone: "+15555555555", Content: "test message"})
+			if err != nil {
+				t.Fatalf("SendMessage: %v", err)
+			}
+			if reported := tt.quota >= 0; res.QuotaReported != reported {
+				t.Errorf("QuotaReported = %v, want %v", res.QuotaReported, reported)
+			}
+
+			quota, err := texter.Quota()
+			if tt.quota < 0 {
+				if !errors.Is(err, ErrQuotaUnavailable) {
+					t.Errorf("Quota = %d, %v, want ErrQuotaUnavailable", quota, err)
+				}
+				return
+			}
+			if err != nil || quota != tt.quota {
+				t.Errorf("Quota = %d, %v, want %d", quota, err, tt.quota)
+			}
+		})
+	}
+}
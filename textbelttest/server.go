@@ -0,0 +1,279 @@
+// Package textbelttest provides a fake textbelt server for testing code using the textbelt package
+package textbelttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultQuota is the quota every key of a new Server starts with
+const DefaultQuota = 100
+
+// Request is a request received by the Server
+type Request struct {
+	Method string     // HTTP method of the request
+	Path   string     // Path of the request, e.g. /text
+	Values url.Values // Form, JSON or query values of the request
+}
+
+// Server is a fake textbelt API implementing /text, /status/{id}, /quota/{key}, /otp/generate
+// and /otp/verify. Pass its URL to textbelt.WithURL. Keys ending in _test are accepted without
+// using quota and report the quota of the key without the suffix, like on textbelt. It is safe
+// for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	requests  []Request
+	quota     map[string]int
+	statuses  map[string]string
+	otps      map[string]string
+	responses map[string]response
+	nextID    int
+}
+
+type response struct {
+	statusCode int
+	body       string
+}
+
+// NewFakeServer starts a new fake textbelt server, call Close once done
+func NewFakeServer() *Server {
+	s := &Server{
+		quota:     make(map[string]int),
+		statuses:  make(map[string]string),
+		otps:      make(map[string]string),
+		responses: make(map[string]response),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /text", s.handleText)
+	mux.HandleFunc("GET /status/{id}", s.handleStatus)
+	mux.HandleFunc("GET /quota/{key}", s.handleQuota)
+	mux.HandleFunc("POST /otp/generate", s.handleGenerateOTP)
+	mux.HandleFunc("GET /otp/verify", s.handleVerifyOTP)
+
+	s.Server = httptest.NewServer(s.record(mux))
+	return s
+}
+
+// Requests returns every request received so far, in order
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// SetQuota sets the quota remaining for key
+func (s *Server) SetQuota(key string, remaining int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.quota[key] = remaining
+}
+
+// SetStatus sets the status Status reports for the message id, sent messages are DELIVERED by default
+func (s *Server) SetStatus(id, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.statuses[id] = status
+}
+
+// SetResponse makes every following request to path, e.g. /text, answer with statusCode and body
+// instead of the simulated response. An empty body restores the simulated response.
+func (s *Server) SetResponse(path string, statusCode int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if body == "" {
+		delete(s.responses, path)
+		return
+	}
+	s.responses[path] = response{
+		statusCode: statusCode,
+		body:       body,
+	}
+}
+
+// record stores the request and returns the configured response if any
+func (s *Server) record(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		values, err := requestValues(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Values: values,
+		})
+		resp, ok := s.responses[r.URL.Path]
+		if !ok && strings.HasPrefix(r.URL.Path, "/status/") {
+			resp, ok = s.responses["/status"]
+		}
+		if !ok && strings.HasPrefix(r.URL.Path, "/quota/") {
+			resp, ok = s.responses["/quota"]
+		}
+		s.mu.Unlock()
+
+		if ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(resp.statusCode)
+			fmt.Fprint(w, resp.body)
+			return
+		}
+
+		r.Form = values
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleText(w http.ResponseWriter, r *http.Request) {
+	if r.Form.Get("phone") == "" || r.Form.Get("message") == "" {
+		writeJSON(w, map[string]any{"success": false, "error": "Incomplete request"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	remaining, ok := s.use(r.Form.Get("key"))
+	if !ok {
+		writeJSON(w, map[string]any{"success": false, "error": "Out of quota", "quotaRemaining": 0})
+		return
+	}
+
+	id := s.newID()
+	s.statuses[id] = "DELIVERED"
+	writeJSON(w, map[string]any{"success": true, "textId": id, "quotaRemaining": remaining})
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status, ok := s.statuses[r.PathValue("id")]
+	if !ok {
+		status = "UNKNOWN"
+	}
+	writeJSON(w, map[string]any{"success": true, "status": status})
+}
+
+func (s *Server) handleQuota(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.TrimSuffix(r.PathValue("key"), "_test")
+	writeJSON(w, map[string]any{"success": true, "quotaRemaining": s.remaining(key)})
+}
+
+func (s *Server) handleGenerateOTP(w http.ResponseWriter, r *http.Request) {
+	if r.Form.Get("phone") == "" || r.Form.Get("userid") == "" {
+		writeJSON(w, map[string]any{"success": false, "error": "Incomplete request"})
+		return
+	}
+
+	length, err := strconv.Atoi(r.Form.Get("length"))
+	if err != nil || length <= 0 {
+		length = 6
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	remaining, ok := s.use(r.Form.Get("key"))
+	if !ok {
+		writeJSON(w, map[string]any{"success": false, "error": "Out of quota", "quotaRemaining": 0})
+		return
+	}
+
+	id := s.newID()
+	otp := fmt.Sprintf("%0*d", length, s.nextID)
+	otp = otp[len(otp)-length:]
+	s.otps[r.Form.Get("userid")] = otp
+	s.statuses[id] = "DELIVERED"
+	writeJSON(w, map[string]any{"success": true, "textId": id, "quotaRemaining": remaining, "otp": otp})
+}
+
+func (s *Server) handleVerifyOTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	otp, ok := s.otps[r.Form.Get("userid")]
+	valid := ok && otp == r.Form.Get("otp")
+	if valid {
+		delete(s.otps, r.Form.Get("userid"))
+	}
+	writeJSON(w, map[string]any{"success": true, "isValidOtp": valid})
+}
+
+// use takes one message from the quota of key, it must be called with mu held
+func (s *Server) use(key string) (remaining int, ok bool) {
+	if strings.HasSuffix(key, "_test") {
+		return s.remaining(strings.TrimSuffix(key, "_test")), true
+	}
+
+	remaining = s.remaining(key)
+	if remaining <= 0 {
+		return 0, false
+	}
+	remaining--
+	s.quota[key] = remaining
+	return remaining, true
+}
+
+// remaining returns the quota of key, it must be called with mu held
+func (s *Server) remaining(key string) int {
+	if remaining, ok := s.quota[key]; ok {
+		return remaining
+	}
+	return DefaultQuota
+}
+
+// newID returns the next message ID, it must be called with mu held
+func (s *Server) newID() string {
+	s.nextID++
+	return strconv.Itoa(s.nextID)
+}
+
+// requestValues returns the query, form or JSON values of the request
+func requestValues(r *http.Request) (url.Values, error) {
+	if r.Method == http.MethodGet {
+		return r.URL.Query(), nil
+	}
+
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mt != "application/json" {
+		if err := r.ParseForm(); err != nil {
+			return nil, err
+		}
+		return r.PostForm, nil
+	}
+
+	var m map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		return nil, err
+	}
+	values := make(url.Values, len(m))
+	for k, v := range m {
+		values.Set(k, fmt.Sprint(v))
+	}
+	return values, nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,161 @@
+package textbelttest_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/lateralusd/textbelt"
+	"github.com/lateralusd/textbelt/textbelttest"
+)
+
+func newServer(t *testing.T) *textbelttest.Server {
+	t.Helper()
+
+	srv := textbelttest.NewFakeServer()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestServerText(t *testing.T) {
+	srv := newServer(t)
+	texter := textbelt.New(textbelt.WithURL(srv.URL), textbelt.WithKey("key"))
+
+	res, err := texter.SendWithResult("+15555555555", "test message")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ID != "1" || res.QuotaRemaining != textbelttest.DefaultQuota-1 {
+		t.Errorf("SendWithResult = %+v, want ID 1 and quota %d", res, textbelttest.DefaultQuota-1)
+	}
+
+	if status, err := texter.Status(res.ID); err != nil || status != textbelt.StatusDelivered {
+		t.Errorf("Status of a sent message = %v, %v, want DELIVERED", status, err)
+	}
+	srv.SetStatus(res.ID, "FAILED")
+	if status, err := texter.Status(res.ID); err != nil || status != textbelt.StatusFailed {
+		t.Errorf("Status after SetStatus = %v, %v, want FAILED", status, err)
+	}
+	if status, err := texter.Status("404"); err != nil || status != textbelt.StatusUnknown {
+		t.Errorf("Status of an unknown message = %v, %v, want UNKNOWN", status, err)
+	}
+
+	if _, err := texter.Send("", "test message"); err == nil {
+		t.Error("Send without a phone succeeded")
+	}
+}
+
+func TestServerQuota(t *testing.T) {
+	srv := newServer(t)
+	srv.SetQuota("key", 1)
+	texter := textbelt.New(textbelt.WithURL(srv.URL), textbelt.WithKey("key"))
+
+	if remaining, err := texter.QuotaFresh(); err != nil || remaining != 1 {
+		t.Fatalf("QuotaFresh = %d, %v, want 1", remaining, err)
+	}
+	if _, err := texter.Send("+15555555555", "test message"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := texter.Send("+15555555555", "test message"); !errors.Is(err, textbelt.ErrQuotaExceeded) {
+		t.Errorf("Send without quota returned %v, want ErrQuotaExceeded", err)
+	}
+
+	// _test keys are accepted without using quota
+	texter.SetTestMode(true)
+	if _, err := texter.Send("+15555555555", "test message"); err != nil {
+		t.Errorf("Send in test mode without quota: %v", err)
+	}
+	if remaining, err := texter.QuotaFresh(); err != nil || remaining != 0 {
+		t.Errorf("QuotaFresh in test mode = %d, %v, want 0", remaining, err)
+	}
+
+	if remaining, err := texter.QuotaFor(t.Context(), "other"); err != nil || remaining != textbelttest.DefaultQuota {
+		t.Errorf("QuotaFor of an unused key = %d, %v, want %d", remaining, err, textbelttest.DefaultQuota)
+	}
+}
+
+func TestServerOTP(t *testing.T) {
+	srv := newServer(t)
+	texter := textbelt.New(textbelt.WithURL(srv.URL))
+
+	res, err := texter.GenerateCustomOTPWithResult(&textbelt.CustomOTP{Phone: "+15555555555", UserID: "u1", Length: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.OTP) != 4 {
+		t.Errorf("OTP %q, want 4 digits", res.OTP)
+	}
+
+	for _, tt := range []struct {
+		otp, userid string
+		want        bool
+	}{
+		{otp: res.OTP, userid: "u2"},
+		{otp: "wrong", userid: "u1"},
+		{otp: res.OTP, userid: "u1", want: true},
+		// a verified OTP is used up
+		{otp: res.OTP, userid: "u1"},
+	} {
+		valid, err := texter.VerifyOTP(tt.otp, tt.userid)
+		if err != nil || valid != tt.want {
+			t.Errorf("VerifyOTP(%q, %q) = %v, %v, want %v", tt.otp, tt.userid, valid, err, tt.want)
+		}
+	}
+}
+
+func TestServerRequests(t *testing.T) {
+	srv := newServer(t)
+
+	for _, opts := range [][]func(*textbelt.Textbelt){
+		{textbelt.WithURL(srv.URL), textbelt.WithKey("key")},
+		{textbelt.WithURL(srv.URL), textbelt.WithKey("key"), textbelt.WithJSONRequests()},
+	} {
+		if _, err := textbelt.New(opts...).Send("+15555555555", "test message"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := textbelt.New(textbelt.WithURL(srv.URL)).Status("1"); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := srv.Requests()
+	if len(requests) != 3 {
+		t.Fatalf("recorded %d requests, want 3", len(requests))
+	}
+	for i, req := range requests[:2] {
+		if req.Method != http.MethodPost || req.Path != "/text" {
+			t.Errorf("request %d = %s %s, want POST /text", i, req.Method, req.Path)
+		}
+		if req.Values.Get("phone") != "+15555555555" || req.Values.Get("message") != "test message" || req.Values.Get("key") != "key" {
+			t.Errorf("request %d values = %v", i, req.Values)
+		}
+	}
+	if req := requests[2]; req.Method != http.MethodGet || req.Path != "/status/1" {
+		t.Errorf("request 2 = %s %s, want GET /status/1", req.Method, req.Path)
+	}
+}
+
+func TestServerSetResponse(t *testing.T) {
+	srv := newServer(t)
+	texter := textbelt.New(textbelt.WithURL(srv.URL))
+
+	srv.SetResponse("/text", http.StatusOK, `{"success":false,"error":"Invalid phone number"}`)
+	if _, err := texter.Send("+15555555555", "test message"); !errors.Is(err, textbelt.ErrInvalidPhone) {
+		t.Errorf("Send with an overridden response returned %v, want ErrInvalidPhone", err)
+	}
+	srv.SetResponse("/text", http.StatusOK, "")
+	if _, err := texter.Send("+15555555555", "test message"); err != nil {
+		t.Errorf("Send after restoring the response: %v", err)
+	}
+
+	// /status and /quota override every ID and key
+	srv.SetResponse("/status", http.StatusOK, `{"success":true,"status":"SENDING"}`)
+	if status, err := texter.Status("1"); err != nil || status != textbelt.StatusSending {
+		t.Errorf("Status = %v, %v, want SENDING", status, err)
+	}
+	srv.SetResponse("/quota", http.StatusInternalServerError, `{"success":false,"error":"down"}`)
+	var herr *textbelt.HTTPError
+	if _, err := texter.QuotaFresh(); !errors.As(err, &herr) || herr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("QuotaFresh = %v, want an HTTPError with status 500", err)
+	}
+}
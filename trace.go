@@ -0,0 +1,50 @@
+package textbelt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/lateralusd/textbelt"
+
+// WithTracerProvider enables you to create a span named like "textbelt.Send" for every call.
+// Spans carry the HTTP status code, success and quota remaining but never the key or phone number.
+func WithTracerProvider(tp trace.TracerProvider) func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.tracer = tp.Tracer(tracerName, trace.WithInstrumentationVersion(Version))
+	}
+}
+
+func (t *Textbelt) startSpan(ctx context.Context, op string) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, "textbelt."+op, trace.WithSpanKind(trace.SpanKindClient))
+}
+
+func endSpan(span trace.Span, r *response, err error) {
+	defer span.End()
+
+	if err != nil {
+		var herr *HTTPError
+		if errors.As(err, &herr) {
+			span.SetAttributes(attribute.Int("http.status_code", herr.StatusCode))
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Int("http.status_code", http.StatusOK),
+		attribute.Bool("textbelt.success", r.Success),
+	)
+	if r.QuotaRemaining != nil {
+		span.SetAttributes(attribute.Int("textbelt.quota_remaining", *r.QuotaRemaining))
+	}
+	if !r.Success {
+		span.SetStatus(codes.Error, r.Error)
+	}
+}
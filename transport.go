@@ -0,0 +1,103 @@
+package textbelt
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// WithProxy enables you to send all requests through the proxy at proxyURL, the http, https and
+// socks5 schemes are supported. It is ignored when WithHTTPClient is used, configure the proxy on
+// the transport of your own client instead.
+func WithProxy(proxyURL string) func(*Textbelt) {
+	return func(t *Textbelt) {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			t.setErr(&ValidationError{
+				Field:  "proxy",
+				Reason: err.Error(),
+			})
+			return
+		}
+
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			t.setErr(&ValidationError{
+				Field:  "proxy",
+				Reason: "scheme must be http, https or socks5",
+			})
+			return
+		}
+
+		if u.Host == "" {
+			t.setErr(&ValidationError{
+				Field:  "proxy",
+				Reason: "host is missing",
+			})
+			return
+		}
+
+		t.proxy = u
+	}
+}
+
+// WithTransportTimeouts enables you to limit the single phases of a request: establishing the
+// connection, the TLS handshake and waiting for the response headers once the request was written.
+// Each phase also stays bound by WithTimeout, which limits the whole request including reading the
+// body, so a short dial timeout with a longer overall timeout fails fast on unreachable hosts.
+// Zero keeps the default of the phase. It is ignored when WithHTTPClient is used.
+func WithTransportTimeouts(dialTimeout, tlsHandshakeTimeout, responseHeaderTimeout time.Duration) func(*Textbelt) {
+	return func(t *Textbelt) {
+		if dialTimeout < 0 || tlsHandshakeTimeout < 0 || responseHeaderTimeout < 0 {
+			t.setErr(&ValidationError{
+				Field:  "transport timeouts",
+				Reason: "must not be negative",
+			})
+			return
+		}
+		t.dialTimeout = dialTimeout
+		t.tlsHandshakeTimeout = tlsHandshakeTimeout
+		t.responseHeaderTimeout = responseHeaderTimeout
+	}
+}
+
+// WithInsecureSkipVerify enables you to accept any TLS certificate, e.g. the self-signed one of a
+// self-hosted textbelt in development. WARNING: it disables protection against man-in-the-middle
+// attacks and must never be used in production. It is ignored when WithHTTPClient is used.
+func WithInsecureSkipVerify() func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.insecureSkipVerify = true
+	}
+}
+
+// transport returns the transport of the default client, nil keeps http.DefaultTransport
+func (t *Textbelt) transport() http.RoundTripper {
+	if t.proxy == nil && t.dialTimeout == 0 && t.tlsHandshakeTimeout == 0 && t.responseHeaderTimeout == 0 &&
+		!t.insecureSkipVerify {
+		return nil
+	}
+
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	if t.proxy != nil {
+		tr.Proxy = http.ProxyURL(t.proxy)
+	}
+	if t.dialTimeout > 0 {
+		tr.DialContext = (&net.Dialer{
+			Timeout:   t.dialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+	}
+	if t.tlsHandshakeTimeout > 0 {
+		tr.TLSHandshakeTimeout = t.tlsHandshakeTimeout
+	}
+	if t.responseHeaderTimeout > 0 {
+		tr.ResponseHeaderTimeout = t.responseHeaderTimeout
+	}
+	if t.insecureSkipVerify {
+		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return tr
+}
@@ -0,0 +1,273 @@
+package textbelt
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	minOTPLength   = 4
+	maxOTPLength   = 10
+	maxOTPLifetime = 24 * 60 * 60
+)
+
+// reservedFields can not be set through Message.Extra
+var reservedFields = map[string]bool{
+	"phone":   true,
+	"message": true,
+	"key":     true,
+}
+
+var e164 = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+// ValidationError is returned when the request is rejected locally before reaching textbelt
+type ValidationError struct {
+	Field  string // Name of the invalid field
+	Reason string // Why the field is invalid
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("textbelt: invalid %s: %s", e.Field, e.Reason)
+}
+
+// WithFailFastValidation enables you to stop validating at the first problem and return only its
+// error. By default every problem of a message or OTP is collected and returned joined with errors.Join.
+func WithFailFastValidation() func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.failFast = true
+	}
+}
+
+// validation collects the errors of the local checks
+type validation struct {
+	failFast bool
+	errs     []error
+}
+
+func (t *Textbelt) newValidation() *validation {
+	return &validation{failFast: t.failFast}
+}
+
+// add records err if it is not nil and reports whether validating should stop
+func (v *validation) add(err error) bool {
+	if err == nil {
+		return false
+	}
+	v.errs = append(v.errs, err)
+	return v.failFast
+}
+
+// stopped reports whether an error was recorded in fail-fast mode
+func (v *validation) stopped() bool {
+	return v.failFast && len(v.errs) > 0
+}
+
+// err returns nil, the only error or all errors joined
+func (v *validation) err() error {
+	if len(v.errs) == 1 {
+		return v.errs[0]
+	}
+	return errors.Join(v.errs...)
+}
+
+// WithPhoneValidation enables you to reject phone numbers not in E.164 format, e.g. +15555555555,
+// before sending them to textbelt. It is disabled by default so national formats keep working.
+func WithPhoneValidation(enabled bool) func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.validatePhone = enabled
+	}
+}
+
+// WithContentValidation enables you to reject message content containing NUL or other control
+// characters before sending it to textbelt. Newlines, carriage returns and tabs are allowed.
+func WithContentValidation() func(*Textbelt) {
+	return func(t *Textbelt) {
+		t.validateContent = true
+	}
+}
+
+// checkContent validates the content if WithContentValidation was enabled
+func (t *Textbelt) checkContent(field, content string) error {
+	if !t.validateContent {
+		return nil
+	}
+
+	for i, r := range content {
+		if r == '\n' || r == '\r' || r == '\t' || !unicode.IsControl(r) {
+			continue
+		}
+		return &ValidationError{
+			Field:  field,
+			Reason: fmt.Sprintf("control character %U at byte offset %d", r, i),
+		}
+	}
+	return nil
+}
+
+// checkPhone validates the phone number if WithPhoneValidation was enabled
+func (t *Textbelt) checkPhone(phone string) error {
+	if !t.validatePhone || e164.MatchString(phone) {
+		return nil
+	}
+	return &ValidationError{
+		Field:  "phone",
+		Reason: "not in E.164 format",
+	}
+}
+
+// otpPlaceholder is replaced with the OTP inside CustomOTP.Message
+const otpPlaceholder = "$OTP"
+
+// Validate checks the CustomOTP before it is sent and returns every problem found, joined with
+// errors.Join if there are several:
+//   - Phone must not be empty
+//   - Length must be between 4 and 10, zero or negative uses the server default
+//   - Lifetime must be between 0 and 86400 seconds, zero uses the server default
+//   - Message, if set, must contain $OTP, otherwise the receiver would not get the OTP
+func (otp *CustomOTP) Validate() error {
+	v := &validation{}
+	otp.validate(v)
+	return v.err()
+}
+
+func (otp *CustomOTP) validate(v *validation) {
+	if otp.Phone == "" && v.add(&ValidationError{
+		Field:  "phone",
+		Reason: "is empty",
+	}) {
+		return
+	}
+
+	if otp.Length > 0 && (otp.Length < minOTPLength || otp.Length > maxOTPLength) && v.add(&ValidationError{
+		Field:  "length",
+		Reason: fmt.Sprintf("%d digits, must be between %d and %d", otp.Length, minOTPLength, maxOTPLength),
+	}) {
+		return
+	}
+
+	if (otp.Lifetime < 0 || otp.Lifetime > maxOTPLifetime) && v.add(&ValidationError{
+		Field:  "lifetime",
+		Reason: fmt.Sprintf("%d seconds, must be between 1 and %d", otp.Lifetime, maxOTPLifetime),
+	}) {
+		return
+	}
+
+	if otp.Message != "" && !strings.Contains(otp.Message, otpPlaceholder) {
+		v.add(&ValidationError{
+			Field:  "message",
+			Reason: "does not contain " + otpPlaceholder,
+		})
+	}
+}
+
+// validateMessage runs the local checks of the message and returns its normalized phone number
+func (t *Textbelt) validateMessage(msg *Message) (string, error) {
+	v := t.newValidation()
+	phone := t.validatePhoneNumber(v, msg.Phone)
+	if v.stopped() {
+		return "", v.err()
+	}
+
+	if v.add(t.checkContent("message", msg.Content)) {
+		return "", v.err()
+	}
+
+	if n := utf8.RuneCountInString(msg.WebhookData); n > maxWebhookData && v.add(&ValidationError{
+		Field:  "webhookData",
+		Reason: fmt.Sprintf("%d characters long, at most %d allowed", n, maxWebhookData),
+	}) {
+		return "", v.err()
+	}
+
+	if !msg.SendAt.IsZero() && msg.SendAt.Before(t.now()) && v.add(&ValidationError{
+		Field:  "sendAt",
+		Reason: "time is in the past",
+	}) {
+		return "", v.err()
+	}
+
+	if msg.MediaURL != "" && v.add(checkMediaURL(msg.MediaURL)) {
+		return "", v.err()
+	}
+
+	fields := make([]string, 0, len(msg.Extra))
+	for k := range msg.Extra {
+		if reservedFields[k] {
+			fields = append(fields, k)
+		}
+	}
+	sort.Strings(fields)
+	for _, k := range fields {
+		if v.add(&ValidationError{
+			Field:  "extra",
+			Reason: fmt.Sprintf("field %q is reserved", k),
+		}) {
+			return "", v.err()
+		}
+	}
+
+	if err := v.err(); err != nil {
+		return "", err
+	}
+	return phone, nil
+}
+
+// validateOTP runs the local checks of the OTP and returns its normalized phone number
+func (t *Textbelt) validateOTP(otp *CustomOTP) (string, error) {
+	v := t.newValidation()
+	otp.validate(v)
+	if v.stopped() {
+		return "", v.err()
+	}
+
+	phone := otp.Phone
+	if phone != "" {
+		// an empty phone was already reported by validate
+		phone = t.validatePhoneNumber(v, phone)
+		if v.stopped() {
+			return "", v.err()
+		}
+	}
+
+	v.add(t.checkContent("message", otp.Message))
+	if err := v.err(); err != nil {
+		return "", err
+	}
+	return phone, nil
+}
+
+// validatePhoneNumber normalizes and checks the phone number, recording problems in v
+func (t *Textbelt) validatePhoneNumber(v *validation, phone string) string {
+	normalized, err := t.normalizePhone(phone)
+	if err != nil {
+		v.add(err)
+		return phone
+	}
+	v.add(t.checkPhone(normalized))
+	return normalized
+}
+
+// checkMediaURL validates that the media URL is an absolute http or https URL
+func checkMediaURL(mediaURL string) error {
+	u, err := url.Parse(mediaURL)
+	if err != nil {
+		return &ValidationError{
+			Field:  "mediaUrl",
+			Reason: err.Error(),
+		}
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &ValidationError{
+			Field:  "mediaUrl",
+			Reason: "must be an absolute http or https URL",
+		}
+	}
+	return nil
+}
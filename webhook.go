@@ -0,0 +1,185 @@
+package textbelt
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// SignatureHeader holds the HMAC signature of the webhook request
+	SignatureHeader = "X-textbelt-signature"
+	// TimestampHeader holds the unix timestamp the webhook request was signed at
+	TimestampHeader = "X-textbelt-timestamp"
+
+	// DefaultWebhookTolerance is the maximum age of the webhook timestamp recommended by textbelt
+	DefaultWebhookTolerance = 15 * time.Minute
+)
+
+// maxWebhookBody limits the webhook body read from the request, textbelt payloads are tiny
+const maxWebhookBody = 64 << 10
+
+var (
+	// ErrEmptyWebhook is returned when the webhook request has no body
+	ErrEmptyWebhook = errors.New("textbelt: webhook request body is empty")
+	// ErrWebhookTooLarge is returned when the webhook request body exceeds 64 KiB
+	ErrWebhookTooLarge = errors.New("textbelt: webhook request body too large")
+)
+
+// InboundReply holds the reply textbelt sends to the replyWebhookUrl
+type InboundReply struct {
+	TextID     string `json:"textId"`     // ID of the original message
+	FromNumber string `json:"fromNumber"` // Phone number of the replier
+	Text       string `json:"text"`       // Content of the reply
+
+	// Data is the webhookData of the original message, arbitrary application-defined text of
+	// at most 100 characters, e.g. to correlate the reply with a conversation
+	Data string `json:"data"`
+}
+
+// ParseReplyWebhook decodes the reply textbelt POSTs to the replyWebhookUrl.
+// Both application/json and form-encoded bodies are supported, bodies larger than 64 KiB are
+// rejected with ErrWebhookTooLarge. The request body is left readable so callers can inspect it
+// if decoding fails.
+func ParseReplyWebhook(r *http.Request) (*InboundReply, error) {
+	body, err := readWebhookBody(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var reply InboundReply
+	if isForm(r) {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, fmt.Errorf("textbelt: decoding reply webhook: %w", err)
+		}
+		reply.TextID = values.Get("textId")
+		reply.FromNumber = values.Get("fromNumber")
+		reply.Text = values.Get("text")
+		reply.Data = values.Get("data")
+		return &reply, nil
+	}
+
+	if err := json.Unmarshal(body, &reply); err != nil {
+		return nil, fmt.Errorf("textbelt: decoding reply webhook: %w", err)
+	}
+	return &reply, nil
+}
+
+// StatusUpdate holds the delivery status textbelt sends to the statusCallbackUrl
+type StatusUpdate struct {
+	TextID string        // ID of the message
+	Status MessageStatus // New status of the message
+}
+
+// ParseStatusWebhook decodes the status update textbelt POSTs to the statusCallbackUrl.
+// Both application/json and form-encoded bodies are supported. The request body
+// is left readable so callers can inspect it if decoding fails.
+func ParseStatusWebhook(r *http.Request) (*StatusUpdate, error) {
+	body, err := readWebhookBody(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var update struct {
+		TextID string `json:"textId"`
+		Status string `json:"status"`
+	}
+	if isForm(r) {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, fmt.Errorf("textbelt: decoding status webhook: %w", err)
+		}
+		update.TextID = values.Get("textId")
+		update.Status = values.Get("status")
+	} else if err := json.Unmarshal(body, &update); err != nil {
+		return nil, fmt.Errorf("textbelt: decoding status webhook: %w", err)
+	}
+
+	return &StatusUpdate{
+		TextID: update.TextID,
+		Status: ParseMessageStatus(update.Status),
+	}, nil
+}
+
+// VerifyWebhookSignature checks whether the signature of the webhook is valid for the key,
+// rejecting timestamps older than DefaultWebhookTolerance
+func VerifyWebhookSignature(key, timestamp, signature string, body []byte) bool {
+	return VerifyWebhookSignatureTolerance(key, timestamp, signature, body, DefaultWebhookTolerance)
+}
+
+// VerifyWebhookSignatureTolerance is like VerifyWebhookSignature but rejects timestamps
+// further than tolerance from the current time. Zero tolerance disables the freshness check.
+func VerifyWebhookSignatureTolerance(key, timestamp, signature string, body []byte, tolerance time.Duration) bool {
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	if tolerance > 0 {
+		age := time.Since(time.Unix(sec, 0))
+		if age < 0 {
+			age = -age
+		}
+		if age > tolerance {
+			return false
+		}
+	}
+
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(timestamp))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// VerifyWebhookRequest checks the signature headers of the webhook request. The request
+// body is left readable so it can be parsed afterwards.
+func VerifyWebhookRequest(r *http.Request, key string, tolerance time.Duration) bool {
+	body, err := readWebhookBody(r)
+	if err != nil {
+		return false
+	}
+	return VerifyWebhookSignatureTolerance(key, r.Header.Get(TimestampHeader), r.Header.Get(SignatureHeader), body, tolerance)
+}
+
+// readWebhookBody reads the body up to maxWebhookBody and puts what was read back on the request
+func readWebhookBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, ErrEmptyWebhook
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody+1))
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxWebhookBody {
+		return nil, ErrWebhookTooLarge
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, ErrEmptyWebhook
+	}
+	return body, nil
+}
+
+func isForm(r *http.Request) bool {
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mt == "application/x-www-form-urlencoded"
+}
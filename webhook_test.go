@@ -0,0 +1,166 @@
+package textbelt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseReplyWebhook(t *testing.T) {
+	want := InboundReply{
+		TextID:     "12345",
+		FromNumber: "+15555555555",
+		Text:       "yes",
+	}
+
+	form := url.Values{
+		"textId":     {want.TextID},
+		"fromNumber": {want.FromNumber},
+		"text":       {want.Text},
+	}
+	bodies := map[string]string{
+		"application/json":                  `{"textId":"12345","fromNumber":"+15555555555","text":"yes"}`,
+		"application/x-www-form-urlencoded": form.Encode(),
+	}
+
+	for contentType, body := range bodies {
+		r := httptest.NewRequest("POST", "/reply", strings.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+
+		got, err := ParseReplyWebhook(r)
+		if err != nil {
+			t.Fatalf("%s: %v", contentType, err)
+		}
+		if *got != want {
+			t.Errorf("%s: got %+v, want %+v", contentType, *got, want)
+		}
+	}
+}
+
+func TestParseReplyWebhookData(t *testing.T) {
+	want := InboundReply{
+		TextID:     "12345",
+		FromNumber: "+15555555555",
+		Text:       "yes",
+		Data:       "conversation=42",
+	}
+
+	form := url.Values{
+		"textId":     {want.TextID},
+		"fromNumber": {want.FromNumber},
+		"text":       {want.Text},
+		"data":       {want.Data},
+	}
+	bodies := map[string]string{
+		"application/json":                  `{"textId":"12345","fromNumber":"+15555555555","text":"yes","data":"conversation=42"}`,
+		"application/x-www-form-urlencoded": form.Encode(),
+	}
+
+	for contentType, body := range bodies {
+		r := httptest.NewRequest("POST", "/reply", strings.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+
+		got, err := ParseReplyWebhook(r)
+		if err != nil {
+			t.Fatalf("%s: %v", contentType, err)
+		}
+		if *got != want {
+			t.Errorf("%s: got %+v, want %+v", contentType, *got, want)
+		}
+	}
+}
+
+func TestParseReplyWebhookBodyLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want error
+	}{
+		{name: "empty", body: "", want: ErrEmptyWebhook},
+		{name: "whitespace", body: " \n", want: ErrEmptyWebhook},
+		{name: "too large", body: `{"text":"` + strings.Repeat("a", maxWebhookBody) + `"}`, want: ErrWebhookTooLarge},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", "/reply", strings.NewReader(tt.body))
+		r.Header.Set("Content-Type", "application/json")
+
+		if _, err := ParseReplyWebhook(r); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+// sign returns the signature textbelt sends for the body signed at timestamp
+func sign(key, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(timestamp))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyWebhookSignatureTolerance(t *testing.T) {
+	const key = "key"
+	body := []byte(`{"textId":"12345","text":"yes"}`)
+	unix := func(d time.Duration) string {
+		return strconv.FormatInt(time.Now().Add(d).Unix(), 10)
+	}
+	now := unix(0)
+
+	tests := []struct {
+		name      string
+		timestamp string
+		signature string
+		body      []byte
+		tolerance time.Duration
+		want      bool
+	}{
+		{name: "valid", timestamp: now, signature: sign(key, now, body), body: body, tolerance: time.Minute, want: true},
+		{name: "tampered body", timestamp: now, signature: sign(key, now, body), body: []byte(`{"textId":"12345","text":"no"}`), tolerance: time.Minute},
+		{name: "wrong key", timestamp: now, signature: sign("other", now, body), body: body, tolerance: time.Minute},
+		{name: "non-hex signature", timestamp: now, signature: "not hex", body: body, tolerance: time.Minute},
+		{name: "empty signature", timestamp: now, signature: "", body: body, tolerance: time.Minute},
+		{name: "invalid timestamp", timestamp: "yesterday", signature: sign(key, "yesterday", body), body: body, tolerance: time.Minute},
+		{name: "stale timestamp", timestamp: unix(-time.Hour), signature: sign(key, unix(-time.Hour), body), body: body, tolerance: time.Minute},
+		{name: "future timestamp", timestamp: unix(time.Hour), signature: sign(key, unix(time.Hour), body), body: body, tolerance: time.Minute},
+		{name: "zero tolerance", timestamp: unix(-time.Hour), signature: sign(key, unix(-time.Hour), body), body: body, want: true},
+		{name: "signed timestamp replaced", timestamp: now, signature: sign(key, unix(-time.Hour), body), body: body},
+	}
+
+	for _, tt := range tests {
+		if got := VerifyWebhookSignatureTolerance(key, tt.timestamp, tt.signature, tt.body, tt.tolerance); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyWebhookRequest(t *testing.T) {
+	const key = "key"
+	body := `{"textId":"12345","fromNumber":"+15555555555","text":"yes"}`
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+
+	r := httptest.NewRequest("POST", "/reply", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set(TimestampHeader, now)
+	r.Header.Set(SignatureHeader, sign(key, now, []byte(body)))
+
+	if !VerifyWebhookRequest(r, key, DefaultWebhookTolerance) {
+		t.Fatal("valid request rejected")
+	}
+	if VerifyWebhookRequest(r, "other", DefaultWebhookTolerance) {
+		t.Error("request accepted with the wrong key")
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil || string(b) != body {
+		t.Errorf("body after verifying = %q, %v, want it left readable", b, err)
+	}
+}